@@ -2,13 +2,17 @@ package backup
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"strings"
 
+	"github.com/brightpuddle/goaci"
+	"github.com/brightpuddle/goaci/meta"
 	"github.com/tidwall/gjson"
 )
 
@@ -19,6 +23,26 @@ type Client struct {
 	DNs map[string]*Res
 	// Classes is the class to object(s) mapping index.
 	Classes map[string][]*Res
+	// Children is the parent DN to child object(s) mapping index.
+	Children map[string][]*Res
+	// Files are the non-configuration files in the backup by archive path,
+	// e.g. idconfig/*_idfile.xml and packages/*.zip.
+	Files map[string][]byte
+	// Allocations is the object DN to identifier allocation(s) mapping index, e.g. pcTags.
+	Allocations map[string][]Allocation
+	// Nodes are the fabric nodes, sorted by node ID.
+	Nodes []Node
+	// Packages are the L4-L7 device packages, sorted by archive path.
+	Packages []Package
+	// Warnings are the files that couldn't be parsed, e.g. a corrupt device package.
+	// These files are left out of the inventory and packages, but the configuration is still loaded.
+	Warnings []string
+	// config is the archive path of the configuration file.
+	config string
+	// key is the AES key for secure properties, derived from the passphrase.
+	key []byte
+	// redact indicates that secure properties are masked in results.
+	redact bool
 }
 
 func fmtRn(template string, record gjson.Result) (rn string) {
@@ -35,7 +59,7 @@ func fmtRn(template string, record gjson.Result) (rn string) {
 		switch {
 		case c == '{': // start of a variable
 			state.inVariable = true
-		case c == '[' || c == ']':
+		case state.inVariable && (c == '[' || c == ']'): // bracketed variable, i.e. {[name]}
 			state.isBracketed = true
 		case c == '}': // end of a variable
 			value := record.Get(state.varName).Str
@@ -58,23 +82,47 @@ func buildDn(record gjson.Result, parentDn []string, class string) ([]string, er
 	// If record already has a DN just return it
 	dn := record.Get("dn").Str
 	if dn != "" {
-		return strings.Split(dn, "/"), nil
+		return splitDn(dn), nil
 	}
 
 	// Get the RN template from the lookup table
-	rnTemplate, ok := rnTemplates[class]
+	template, ok := rnTemplate(class)
 	if !ok {
 		return []string{}, fmt.Errorf("rn template not found for %s", class)
 	}
 
 	// Parse the RN template
-	rn := fmtRn(rnTemplate, record)
+	rn := fmtRn(template, record)
 
 	return append(parentDn, rn), nil
 }
 
+// splitDn splits a DN into RNs, see goaci.DN.
+func splitDn(dn string) []string {
+	return goaci.DN(dn).RNs()
+}
+
+// parentOf returns the parent DN of a DN.
+func parentOf(dn string) string {
+	return string(goaci.DN(dn).Parent())
+}
+
+// newClient creates an empty client with initialized indexes.
+func newClient() Client {
+	return Client{
+		DNs:         make(map[string]*Res),
+		Classes:     make(map[string][]*Res),
+		Children:    make(map[string][]*Res),
+		Files:       make(map[string][]byte),
+		Allocations: make(map[string][]Allocation),
+	}
+}
+
 // NewClient creates a new backup file client.
-func NewClient(src string) (Client, error) {
+// Both JSON and XML backup files are supported.
+// Pass modifiers in to modify the behavior of the client, e.g.
+//  client, _ := NewClient("config.tar.gz", EncryptSecure("my-passphrase"), Redact)
+func NewClient(src string, mods ...func(*Client)) (Client, error) {
 	// Open backup file
 	f, err := os.Open(src)
 	if err != nil {
@@ -90,9 +138,14 @@ func NewClient(src string) (Client, error) {
 	defer gzf.Close()
 
 	// Initialize client
-	client := Client{
-		DNs:     make(map[string]*Res),
-		Classes: make(map[string][]*Res),
+	client := newClient()
+	for _, mod := range mods {
+		mod(&client)
+	}
+	if client.key != nil || client.redact {
+		if err := meta.Check(); err != nil {
+			return Client{}, err
+		}
 	}
 
 	// Untar backup tar file
@@ -105,27 +158,59 @@ func NewClient(src string) (Client, error) {
 		} else if err != nil {
 			return Client{}, err
 		}
-		info := header.FileInfo()
-		name := info.Name()
-		if strings.HasSuffix(name, ".json") {
-			data, err := ioutil.ReadAll(tarReader)
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return Client{}, err
+		}
+
+		// Configuration files are in the root of the archive.
+		// Other files, e.g. idconfig/*, are kept as is.
+		name := strings.TrimPrefix(header.Name, "./")
+		isConfig := !strings.Contains(name, "/")
+		switch {
+		case isConfig && strings.HasSuffix(name, ".json"):
+			client.addToDB(gjson.ParseBytes(data))
+		case isConfig && strings.HasSuffix(name, ".xml"):
+			root, err := xmlToJSON(bytes.NewReader(data))
 			if err != nil {
-				return Client{}, err
+				return Client{}, fmt.Errorf("%s: %v", name, err)
 			}
-			client.addToDB(gjson.ParseBytes(data))
+			client.addToDB(root)
+		default:
+			client.Files[name] = data
+			continue
+		}
+		if client.config == "" {
+			client.config = name
+		}
+	}
+
+	client.loadInventory()
+	client.loadPackages()
+	if client.key != nil {
+		if err := client.decryptAll(); err != nil {
+			return Client{}, err
 		}
 	}
 	return client, nil
 }
 
 func (client Client) addToDB(root gjson.Result) {
+	client.addTree(root, nil)
+}
+
+// addTree adds an object and its children to the indexes under the given parent DN.
+func (client Client) addTree(root gjson.Result, rootParentDn []string) {
 	type MO struct {
 		object   gjson.Result
 		parentDn []string
 		class    string
 	}
 	// Create stack and populate root node
-	stack := []MO{{object: root}}
+	stack := []MO{{object: root, parentDn: rootParentDn}}
 
 	for len(stack) > 0 {
 		// Pop item off stack j
@@ -150,17 +235,166 @@ func (client Client) addToDB(root gjson.Result) {
 				Set(mo.class+".attributes.dn", dn).                           // Fix the DN
 				Res()
 
+			parentDn := strings.Join(mo.parentDn, "/")
 			client.DNs[dn] = &json
 			client.Classes[mo.class] = append(client.Classes[mo.class], &json)
+			client.Children[parentDn] = append(client.Children[parentDn], &json)
 		}
 
-		// Add children of this MO to stack
-		for _, child := range moBody.Get("children").Array() {
-			stack = append(stack, MO{object: child, parentDn: thisDn})
+		// Add children of this MO to stack in reverse to keep the document order
+		children := moBody.Get("children").Array()
+		for i := len(children) - 1; i >= 0; i-- {
+			stack = append(stack, MO{object: children[i], parentDn: thisDn})
 		}
 	}
 }
 
+// notFoundError is returned for a missing class or DN.
+// Check for this with goaci.IsNotFound.
+type notFoundError string
+
+func (err notFoundError) Error() string {
+	return string(err) + " not found"
+}
+
+// NotFound indicates that this is a not found error.
+func (err notFoundError) NotFound() bool {
+	return true
+}
+
+// className returns the class of an object, i.e. its top level key.
+func className(obj Res) string {
+	return goaci.ClassName(obj)
+}
+
+// dnOf returns the DN of an object.
+func dnOf(obj Res) string {
+	return obj.Get(className(obj) + ".attributes.dn").Str
+}
+
+// list builds a JSON list from a set of objects.
+func (client Client) list(objs []*Res) Res {
+	raws := make([]string, len(objs))
+	for i, obj := range objs {
+		raws[i] = client.redacted(*obj).Raw
+	}
+	return gjson.Parse("[" + strings.Join(raws, ",") + "]")
+}
+
+// newReq builds a request for the given URI and applies the request modifiers.
+func newReq(uri string, mods ...func(*Req)) Req {
+	httpReq, _ := http.NewRequest("GET", uri, nil)
+	req := Req{HttpReq: httpReq}
+	for _, mod := range mods {
+		mod(&req)
+	}
+	return req
+}
+
+// subtree returns all descendants of a DN in depth-first order.
+func (client Client) subtree(dn string) (objs []*Res) {
+	for _, child := range client.Children[dn] {
+		objs = append(objs, child)
+		objs = append(objs, client.subtree(dnOf(*child))...)
+	}
+	return objs
+}
+
+// tree builds the nested JSON for an object and its subtree, leaving out the omitted attributes.
+// Only the top level object is guaranteed to retain its DN. Child DNs are left out where
+// they can be derived from the parent DN and naming properties.
+// If set, prepare is applied to each object first.
+func (client Client) tree(obj Res, omit map[string]bool, top bool, prepare func(Res) (Res, error)) (Body, error) {
+	if prepare != nil {
+		var err error
+		if obj, err = prepare(obj); err != nil {
+			return Body{}, err
+		}
+	}
+	class := className(obj)
+	attrs := obj.Get(class + ".attributes")
+	dn := attrs.Get("dn").Str
+	keepDn := top
+	if !keepDn {
+		template, ok := rnTemplate(class)
+		keepDn = !ok || dn != parentOf(dn)+"/"+fmtRn(template, attrs)
+	}
+
+	body := Body{}.SetRaw(class+".attributes", "{}")
+	attrs.ForEach(func(key, value Res) bool {
+		if omit[key.Str] || (key.Str == "dn" && !keepDn) {
+			return true
+		}
+		body = body.Set(class+".attributes."+key.Str, value.String())
+		return true
+	})
+	for _, child := range client.Children[dn] {
+		childBody, err := client.tree(*child, omit, false, prepare)
+		if err != nil {
+			return Body{}, err
+		}
+		body = body.SetRaw(class+".children.-1", childBody.Str)
+	}
+	return body, nil
+}
+
+// query applies the query parameters of a request to a set of objects.
+// This handles query-target, target-subtree-class and query-target-filter.
+func (client Client) query(objs []*Res, req Req) ([]*Res, error) {
+	q := req.HttpReq.URL.Query()
+
+	// Scope
+	var scoped []*Res
+	target := q.Get("query-target")
+	switch target {
+	case "", "self":
+		scoped = objs
+	case "children":
+		for _, obj := range objs {
+			scoped = append(scoped, client.Children[dnOf(*obj)]...)
+		}
+	case "subtree":
+		for _, obj := range objs {
+			scoped = append(scoped, obj)
+			scoped = append(scoped, client.subtree(dnOf(*obj))...)
+		}
+	default:
+		return nil, fmt.Errorf("invalid query-target %s", target)
+	}
+
+	// Subtree class restriction
+	if classes := q.Get("target-subtree-class"); classes != "" && target != "" && target != "self" {
+		include := make(map[string]bool)
+		for _, class := range strings.Split(classes, ",") {
+			include[strings.TrimSpace(class)] = true
+		}
+		var included []*Res
+		for _, obj := range scoped {
+			if include[className(*obj)] {
+				included = append(included, obj)
+			}
+		}
+		scoped = included
+	}
+
+	// Filter
+	expr := q.Get("query-target-filter")
+	if expr == "" {
+		return scoped, nil
+	}
+	filter, err := ParseFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	var matched []*Res
+	for _, obj := range scoped {
+		if filter.Match(*obj) {
+			matched = append(matched, obj)
+		}
+	}
+	return matched, nil
+}
+
 // GetClass queries the backup file for an MO class.
 // This returns a list of objects, i.e. the contents of imdata:
 //  [
@@ -170,12 +404,20 @@ func (client Client) addToDB(root gjson.Result) {
 //      }
 //    }
 //  ]
+//
+// The query-target, target-subtree-class and query-target-filter query parameters are supported, e.g.
+//  client.GetClass("fvBD", goaci.Query("query-target-filter", `eq(fvBD.name,"bd-name")`))
 func (client Client) GetClass(class string, mods ...func(*Req)) (Res, error) {
-	res, ok := client.Classes[class]
+	objs, ok := client.Classes[class]
 	if !ok {
-		return Res{}, fmt.Errorf("%s not found", class)
+		return Res{}, notFoundError(class)
 	}
-	return gjson.Parse(fmt.Sprintf("%v", res)), nil
+	req := newReq("/api/class/"+class, mods...)
+	res, err := client.query(objs, req)
+	if err != nil {
+		return Res{}, err
+	}
+	return client.list(res), nil
 }
 
 // GetDn queries the backup for a specific DN.
@@ -188,10 +430,26 @@ func (client Client) GetClass(class string, mods ...func(*Req)) (Res, error) {
 //
 // For unknown class types, retrieve the attributes with a wildcard:
 //  res.Get("*.attributes")
+//
+// The query-target, target-subtree-class and query-target-filter query parameters are supported.
+// With a query-target of children or subtree this returns a list of objects as with GetClass.
+// An empty result is returned if the object does not match the query-target-filter.
 func (client Client) GetDn(dn string, mods ...func(*Req)) (Res, error) {
-	res, ok := client.DNs[dn]
+	obj, ok := client.DNs[dn]
 	if !ok {
-		return Res{}, fmt.Errorf("%s not fund", dn)
+		return Res{}, notFoundError(dn)
+	}
+	req := newReq("/api/mo/"+dn, mods...)
+	res, err := client.query([]*Res{obj}, req)
+	if err != nil {
+		return Res{}, err
+	}
+	switch req.HttpReq.URL.Query().Get("query-target") {
+	case "children", "subtree":
+		return client.list(res), nil
+	}
+	if len(res) == 0 {
+		return Res{}, nil
 	}
-	return *res, nil
+	return client.redacted(*res[0]), nil
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/brightpuddle/goaci"
+	"github.com/brightpuddle/goaci/meta"
 	"github.com/stretchr/testify/assert"
 	"github.com/tidwall/gjson"
 )
@@ -31,6 +33,13 @@ func TestFmtRN(t *testing.T) {
 
 	record = Body{}.Set("key2", "two").Set("key3", "three").Res()
 	assert.Equal(t, "one-two-three", fmtRn("one-{key2}-{key3}", record))
+
+	// Property after a bracketed property, from the metadata and in the APIC form
+	defer registerTestMeta(t)()
+	class, _ := meta.Lookup("fvnsVlanInstP")
+	record = Body{}.Set("name", "x").Set("allocMode", "dynamic").Res()
+	assert.Equal(t, "vlanns-[x]-dynamic", fmtRn(class.RnFormat, record))
+	assert.Equal(t, "vlanns-[x]-dynamic", fmtRn("vlanns-[{name}]-{allocMode}", record))
 }
 
 // TestBuildDN tests the buildDn function.
@@ -60,6 +69,23 @@ func TestNewClient(t *testing.T) {
 	// Not a gzip file
 	_, err = NewClient("./testdata/config.json")
 	assert.Error(t, err)
+
+	// XML backup file
+	bkup, err := NewClient("./testdata/xml_config.tar.gz")
+	assert.NoError(t, err)
+	res, _ := bkup.GetDn("uni/tn-b")
+	if !assert.Equal(t, "b", res.Get("fvTenant.attributes.name").Str) {
+		fmt.Println(res.Get("@pretty"))
+	}
+}
+
+// TestSplitDn tests the splitDn and parentOf functions.
+func TestSplitDn(t *testing.T) {
+	dn := "uni/tn-a/out-x/lnodep-y/rsnodeL3OutAtt-[topology/pod-1/node-101]"
+	assert.Equal(t, []string{"uni", "tn-a", "out-x", "lnodep-y", "rsnodeL3OutAtt-[topology/pod-1/node-101]"}, splitDn(dn))
+	assert.Equal(t, "uni/tn-a/out-x/lnodep-y", parentOf(dn))
+	assert.Equal(t, "", parentOf("uni"))
+	assert.Empty(t, splitDn(""))
 }
 
 // TestClientGetDn tests the Client::GetDn method.
@@ -101,3 +127,48 @@ func TestClientGetClass(t *testing.T) {
 		fmt.Println(res.Get("@pretty"))
 	}
 }
+
+// TestClientQuery tests query parameter handling for the Client::GetClass and Client::GetDn methods.
+func TestClientQuery(t *testing.T) {
+	bkup, _ := testClient()
+
+	// Filter on class query
+	res, err := bkup.GetClass("fvTenant", goaci.Query("query-target-filter", `eq(fvTenant.name,"b")`))
+	assert.NoError(t, err)
+	if !assert.Len(t, res.Array(), 1) {
+		fmt.Println(res.Get("@pretty"))
+	}
+	assert.Equal(t, "uni/tn-b", res.Get("0.fvTenant.attributes.dn").Str)
+
+	// Invalid filter
+	_, err = bkup.GetClass("fvTenant", goaci.Query("query-target-filter", `eq(fvTenant.name`))
+	assert.Error(t, err)
+
+	// Filter on DN query
+	res, err = bkup.GetDn("uni/tn-a", goaci.Query("query-target-filter", `eq(fvTenant.name,"b")`))
+	assert.NoError(t, err)
+	assert.False(t, res.Exists())
+
+	// Children
+	res, _ = bkup.GetDn("uni", goaci.Query("query-target", "children"))
+	assert.Len(t, res.Array(), 2)
+
+	// Subtree with filter
+	res, _ = bkup.GetClass("polUni",
+		goaci.Query("query-target", "subtree"),
+		goaci.Query("query-target-filter", `eq(fvTenant.name,"a")`))
+	if !assert.Len(t, res.Array(), 1) {
+		fmt.Println(res.Get("@pretty"))
+	}
+
+	// Subtree class restriction
+	res, _ = bkup.GetDn("uni",
+		goaci.Query("query-target", "subtree"),
+		goaci.Query("target-subtree-class", "fvTenant"))
+	assert.Len(t, res.Get("#.fvTenant").Array(), 2)
+	assert.Len(t, res.Array(), 2)
+
+	// Invalid query target
+	_, err = bkup.GetDn("uni", goaci.Query("query-target", "invalid"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,252 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/brightpuddle/goaci/meta"
+)
+
+// VolatileAttributes are attributes that change without a configuration change.
+// Pass IgnoreVolatile to Diff to leave these out of the comparison.
+var VolatileAttributes = []string{"childAction", "lcOwn", "modTs", "status", "uid"}
+
+// DiffOptions are the options for comparing backups.
+type DiffOptions struct {
+	// Ignore is the set of attribute names left out of the comparison.
+	Ignore map[string]bool
+	// Scope is the DN of the subtree to compare, e.g. uni/tn-mytenant.
+	// The whole backup is compared by default.
+	Scope string
+	// common restricts the comparison to attributes present in both objects.
+	common bool
+}
+
+// IgnoreAttributes leaves the given attributes out of the comparison.
+func IgnoreAttributes(attrs ...string) func(*DiffOptions) {
+	return func(opts *DiffOptions) {
+		for _, attr := range attrs {
+			opts.Ignore[attr] = true
+		}
+	}
+}
+
+// Scope restricts the comparison to a subtree, e.g.
+//  backup.Diff(before, after, backup.Scope("uni/tn-mytenant"))
+func Scope(dn string) func(*DiffOptions) {
+	return func(opts *DiffOptions) {
+		opts.Scope = dn
+	}
+}
+
+// inScope indicates whether a DN is in the comparison scope.
+func (opts DiffOptions) inScope(dn string) bool {
+	return opts.Scope == "" || dn == opts.Scope || strings.HasPrefix(dn, opts.Scope+"/")
+}
+
+// IgnoreVolatile leaves the VolatileAttributes out of the comparison.
+func IgnoreVolatile(opts *DiffOptions) {
+	IgnoreAttributes(VolatileAttributes...)(opts)
+}
+
+// AttributeChange is an attribute with a different value in each backup.
+// An attribute missing from one of the backups has an empty value.
+type AttributeChange struct {
+	Name string `json:"name"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// ObjectDiff is an added, removed or modified managed object.
+type ObjectDiff struct {
+	Dn    string `json:"dn"`
+	Class string `json:"class"`
+	// Attributes are the attributes of an added or removed object.
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Changes are the changed attributes of a modified object.
+	Changes []AttributeChange `json:"changes,omitempty"`
+}
+
+// DiffResult is the difference between two backups.
+// Objects are sorted by DN.
+type DiffResult struct {
+	Added    []ObjectDiff `json:"added"`
+	Removed  []ObjectDiff `json:"removed"`
+	Modified []ObjectDiff `json:"modified"`
+}
+
+// Empty indicates that the backups have no differences.
+func (diff DiffResult) Empty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Modified) == 0
+}
+
+// JSON returns the diff as indented JSON.
+func (diff DiffResult) JSON() string {
+	data, _ := json.MarshalIndent(diff, "", "  ")
+	return string(data)
+}
+
+// String returns the diff as human-readable text, e.g.
+//  + fvTenant uni/tn-new
+//  - fvBD uni/tn-a/BD-old
+//  ~ fvBD uni/tn-a/BD-web
+//      descr: "before" -> "after"
+func (diff DiffResult) String() string {
+	var text strings.Builder
+	for _, obj := range diff.Added {
+		fmt.Fprintf(&text, "+ %s %s\n", obj.Class, obj.Dn)
+	}
+	for _, obj := range diff.Removed {
+		fmt.Fprintf(&text, "- %s %s\n", obj.Class, obj.Dn)
+	}
+	for _, obj := range diff.Modified {
+		fmt.Fprintf(&text, "~ %s %s\n", obj.Class, obj.Dn)
+		for _, change := range obj.Changes {
+			fmt.Fprintf(&text, "    %s: %q -> %q\n", change.Name, change.Old, change.New)
+		}
+	}
+	return text.String()
+}
+
+// attributes returns an object's attributes, less the DN and ignored attributes.
+func attributes(obj Res, ignore map[string]bool) map[string]string {
+	attrs := make(map[string]string)
+	obj.Get(className(obj) + ".attributes").ForEach(func(key, value Res) bool {
+		if key.Str != "dn" && !ignore[key.Str] {
+			attrs[key.Str] = value.String()
+		}
+		return true
+	})
+	return attrs
+}
+
+// compareAttributes returns the changed attributes of an object, sorted by name.
+// With common set only attributes present in both objects are compared.
+func compareAttributes(before, after map[string]string, common bool) (changes []AttributeChange) {
+	names := make(map[string]bool)
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+	for name := range names {
+		oldValue, inOld := before[name]
+		newValue, inNew := after[name]
+		if common && !(inOld && inNew) {
+			continue
+		}
+		if oldValue != newValue {
+			changes = append(changes, AttributeChange{Name: name, Old: oldValue, New: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// addDefaults adds the default values of configurable attributes in a live object that are
+// missing from the backup object, since configuration exports can leave out attributes with
+// default values. Needs the class metadata (see meta); without it only attributes in both
+// objects are compared.
+func addDefaults(class string, attrs, live map[string]string) {
+	c, ok := meta.Lookup(class)
+	if !ok {
+		return
+	}
+	for name := range live {
+		if _, ok := attrs[name]; ok {
+			continue
+		}
+		if prop, ok := c.Properties[name]; ok && prop.Configurable {
+			attrs[name] = prop.Default
+		}
+	}
+}
+
+// sortDiff sorts the objects in a diff by DN.
+func sortDiff(diff *DiffResult) {
+	for _, objs := range [][]ObjectDiff{diff.Added, diff.Removed, diff.Modified} {
+		sort.Slice(objs, func(i, j int) bool { return objs[i].Dn < objs[j].Dn })
+	}
+}
+
+// Diff compares two backups and reports the added, removed and modified objects in b relative to a.
+// Pass modifiers to change the comparison, e.g.
+//  diff := backup.Diff(before, after, backup.IgnoreVolatile, backup.IgnoreAttributes("descr"))
+//  fmt.Print(diff)
+func Diff(a, b Client, mods ...func(*DiffOptions)) DiffResult {
+	return compare(a, b, newDiffOptions(mods...))
+}
+
+// newDiffOptions creates the comparison options and applies the modifiers.
+func newDiffOptions(mods ...func(*DiffOptions)) DiffOptions {
+	opts := DiffOptions{Ignore: make(map[string]bool)}
+	for _, mod := range mods {
+		mod(&opts)
+	}
+	return opts
+}
+
+// compare compares two backups with the given options.
+func compare(a, b Client, opts DiffOptions) DiffResult {
+	diff := DiffResult{}
+	for dn, oldObj := range a.DNs {
+		if !opts.inScope(dn) {
+			continue
+		}
+		class := className(*oldObj)
+		newObj, ok := b.DNs[dn]
+		if !ok {
+			diff.Removed = append(diff.Removed, ObjectDiff{
+				Dn:         dn,
+				Class:      class,
+				Attributes: attributes(*oldObj, opts.Ignore),
+			})
+			continue
+		}
+		oldAttrs := attributes(*oldObj, opts.Ignore)
+		newAttrs := attributes(*newObj, opts.Ignore)
+		if opts.common {
+			addDefaults(class, oldAttrs, newAttrs)
+		}
+		changes := compareAttributes(oldAttrs, newAttrs, opts.common)
+		if len(changes) > 0 {
+			diff.Modified = append(diff.Modified, ObjectDiff{Dn: dn, Class: class, Changes: changes})
+		}
+	}
+	for dn, newObj := range b.DNs {
+		if _, ok := a.DNs[dn]; !ok && opts.inScope(dn) {
+			diff.Added = append(diff.Added, ObjectDiff{
+				Dn:         dn,
+				Class:      className(*newObj),
+				Attributes: attributes(*newObj, opts.Ignore),
+			})
+		}
+	}
+	if a.redact || b.redact {
+		redactDiff(&diff)
+	}
+	sortDiff(&diff)
+	return diff
+}
+
+// redactDiff masks secure properties in a diff.
+func redactDiff(diff *DiffResult) {
+	for _, objs := range [][]ObjectDiff{diff.Added, diff.Removed, diff.Modified} {
+		for _, obj := range objs {
+			for name, value := range obj.Attributes {
+				if isSecure(obj.Class, name) && value != "" {
+					obj.Attributes[name] = Redacted
+				}
+			}
+			for i, change := range obj.Changes {
+				if isSecure(obj.Class, change.Name) {
+					obj.Changes[i].Old = Redacted
+					obj.Changes[i].New = Redacted
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// newTestDiffClients creates two in-memory backups for diff tests.
+func newTestDiffClients() (Client, Client) {
+	a := newClient()
+	a.addToDB(gjson.Parse(`{"polUni":{"attributes":{"dn":"uni"},"children":[
+		{"fvTenant":{"attributes":{"name":"a","descr":"before","modTs":"1"}}},
+		{"fvTenant":{"attributes":{"name":"removed"}}}
+	]}}`))
+	b := newClient()
+	b.addToDB(gjson.Parse(`{"polUni":{"attributes":{"dn":"uni"},"children":[
+		{"fvTenant":{"attributes":{"name":"a","descr":"after","modTs":"2"}}},
+		{"fvTenant":{"attributes":{"name":"added"}}}
+	]}}`))
+	return a, b
+}
+
+// TestDiff tests the Diff function.
+func TestDiff(t *testing.T) {
+	a, b := newTestDiffClients()
+
+	diff := Diff(a, b)
+	if assert.Len(t, diff.Added, 1) {
+		assert.Equal(t, "uni/tn-added", diff.Added[0].Dn)
+		assert.Equal(t, "fvTenant", diff.Added[0].Class)
+		assert.Equal(t, "added", diff.Added[0].Attributes["name"])
+	}
+	if assert.Len(t, diff.Removed, 1) {
+		assert.Equal(t, "uni/tn-removed", diff.Removed[0].Dn)
+	}
+	if assert.Len(t, diff.Modified, 1) {
+		assert.Equal(t, []AttributeChange{
+			{Name: "descr", Old: "before", New: "after"},
+			{Name: "modTs", Old: "1", New: "2"},
+		}, diff.Modified[0].Changes)
+	}
+
+	// Ignore volatile and explicit attributes
+	diff = Diff(a, b, IgnoreVolatile, IgnoreAttributes("descr"))
+	assert.Empty(t, diff.Modified)
+	assert.False(t, diff.Empty())
+
+	// Scoped to a subtree
+	diff = Diff(a, b, Scope("uni/tn-a"))
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Removed)
+	assert.Len(t, diff.Modified, 1)
+
+	// No differences
+	assert.True(t, Diff(a, a).Empty())
+}
+
+// TestDiffOutput tests the DiffResult::JSON and DiffResult::String methods.
+func TestDiffOutput(t *testing.T) {
+	a, b := newTestDiffClients()
+	diff := Diff(a, b, IgnoreVolatile)
+
+	var decoded DiffResult
+	assert.NoError(t, json.Unmarshal([]byte(diff.JSON()), &decoded))
+	assert.Equal(t, diff, decoded)
+
+	assert.Equal(t, `+ fvTenant uni/tn-added
+- fvTenant uni/tn-removed
+~ fvTenant uni/tn-a
+    descr: "before" -> "after"
+`, diff.String())
+}
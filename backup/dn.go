@@ -0,0 +1,200 @@
+package backup
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/brightpuddle/goaci/meta"
+)
+
+// MatchDn returns the most specific class whose RN template matches the last RN of a DN.
+// RN templates are shared by some classes, e.g. subnet-[ip] by fvSubnet and cloudSubnet.
+// Candidates that can't be contained by the parent are ruled out using the metadata models;
+// without them meta.ErrNoModels is returned for such RNs (see MatchRn for all candidates), e.g.
+//  match, _ := backup.MatchDn("uni/tn-a/out-x/lnodep-y/rsnodeL3OutAtt-[topology/pod-1/node-101]")
+//  // {l3extRsNodeL3OutAtt map[tDn:topology/pod-1/node-101]}
+// Use goaci.DN to navigate DNs.
+func MatchDn(dn string) (RnMatch, error) {
+	rn := goaci.DN(dn).RN()
+	matches := MatchRn(rn)
+	if len(matches) == 0 {
+		return RnMatch{}, fmt.Errorf("no rn template matches %s", rn)
+	}
+	parentDn := goaci.DN(dn).Parent()
+	if len(matches) == 1 || parentDn == "" {
+		return matches[0], nil
+	}
+	if err := meta.Check(); err != nil {
+		return RnMatch{}, fmt.Errorf("%s matches several classes: %v", rn, err)
+	}
+	parent, err := MatchDn(string(parentDn))
+	if err != nil {
+		return matches[0], nil
+	}
+	for _, match := range matches {
+		if class, ok := meta.Lookup(match.Class); ok && class.HasParent(parent.Class) {
+			return match, nil
+		}
+	}
+	return matches[0], nil
+}
+
+// rnTemplate returns the RN template of a class from the RN table,
+// or from the registered metadata models for classes missing from the table.
+func rnTemplate(class string) (string, bool) {
+	if template, ok := rnTemplates[class]; ok {
+		return template, true
+	}
+	if c, ok := meta.Lookup(class); ok && c.RnFormat != "" {
+		return c.RnFormat, true
+	}
+	return "", false
+}
+
+// allRnTemplates returns the RN table merged with the RN templates of the registered metadata models.
+func allRnTemplates() map[string]string {
+	templates := make(map[string]string, len(rnTemplates))
+	for _, version := range meta.Versions() {
+		model, _ := meta.Get(version)
+		for name, class := range model.Classes {
+			if class.RnFormat != "" {
+				templates[name] = class.RnFormat
+			}
+		}
+	}
+	for class, template := range rnTemplates {
+		templates[class] = template
+	}
+	return templates
+}
+
+// RnClasses returns the classes with an RN template, sorted, e.g. for class name completion.
+// This includes the classes of the registered metadata models.
+func RnClasses() []string {
+	var classes []string
+	for class := range allRnTemplates() {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// NewRn builds the RN of an object from its class and naming properties, e.g.
+//  backup.NewRn("fvSubnet", map[string]string{"ip": "10.0.0.1/24"}) // subnet-[10.0.0.1/24]
+func NewRn(class string, naming map[string]string) (string, error) {
+	template, ok := rnTemplate(class)
+	if !ok {
+		return "", fmt.Errorf("rn template not found for %s", class)
+	}
+	body := Body{}
+	for key, value := range naming {
+		body = body.Set(key, value)
+	}
+	return fmtRn(template, body.Res()), nil
+}
+
+// RnMatch is a class whose RN template matches an RN.
+type RnMatch struct {
+	Class string
+	// Naming are the naming properties parsed from the RN.
+	Naming map[string]string
+}
+
+// rnPattern is an RN template compiled for matching.
+type rnPattern struct {
+	class string
+	// literal is the number of literal characters in the template, for ranking matches.
+	literal int
+	re      *regexp.Regexp
+	names   []string
+}
+
+var (
+	rnPatternsMu sync.Mutex
+	// rnPatterns are the RN patterns by literal template prefix, i.e. the text before the first property.
+	rnPatterns map[string][]rnPattern
+	// rnPatternsVersions are the metadata versions the patterns were compiled with.
+	rnPatternsVersions = "-"
+	// templateVar matches a property in an RN template, e.g. {name} or {[tDn]}.
+	templateVar = regexp.MustCompile(`\{(\[?)([^}\]]*)\]?\}`)
+)
+
+// compileRnPatterns compiles the RN templates for reverse matching.
+// Patterns are compiled again when metadata models are registered.
+func compileRnPatterns() map[string][]rnPattern {
+	rnPatternsMu.Lock()
+	defer rnPatternsMu.Unlock()
+	versions := strings.Join(meta.Versions(), ",")
+	if versions == rnPatternsVersions {
+		return rnPatterns
+	}
+	rnPatternsVersions = versions
+	rnPatterns = make(map[string][]rnPattern)
+	for class, template := range allRnTemplates() {
+		pattern := rnPattern{class: class}
+		var expr strings.Builder
+		expr.WriteString("^")
+		last := 0
+		locs := templateVar.FindAllStringSubmatchIndex(template, -1)
+		for i, loc := range locs {
+			literal := template[last:loc[0]]
+			pattern.literal += len(literal)
+			expr.WriteString(regexp.QuoteMeta(literal))
+			group := "(.*?)"
+			if i == len(locs)-1 {
+				group = "(.*)"
+			}
+			if loc[3] > loc[2] {
+				group = `\[` + group + `\]`
+			}
+			expr.WriteString(group)
+			pattern.names = append(pattern.names, template[loc[4]:loc[5]])
+			last = loc[1]
+		}
+		pattern.literal += len(template) - last
+		expr.WriteString(regexp.QuoteMeta(template[last:]) + "$")
+		pattern.re = regexp.MustCompile(expr.String())
+
+		prefix := template
+		if len(locs) > 0 {
+			prefix = template[:locs[0][0]]
+		}
+		rnPatterns[prefix] = append(rnPatterns[prefix], pattern)
+	}
+	return rnPatterns
+}
+
+// MatchRn returns the classes whose RN template matches an RN, most specific first,
+// i.e. templates with more literal text rank higher, e.g.
+//  backup.MatchRn("BD-web") // [{fvBD map[name:web]}]
+// RN templates aren't unique, e.g. rsIfPol, so an RN may match several classes.
+func MatchRn(rn string) (matches []RnMatch) {
+	patterns := compileRnPatterns()
+	var found []rnPattern
+	for i := 0; i <= len(rn); i++ {
+		for _, pattern := range patterns[rn[:i]] {
+			if pattern.re.MatchString(rn) {
+				found = append(found, pattern)
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].literal != found[j].literal {
+			return found[i].literal > found[j].literal
+		}
+		return found[i].class < found[j].class
+	})
+	for _, pattern := range found {
+		groups := pattern.re.FindStringSubmatch(rn)
+		naming := make(map[string]string)
+		for i, name := range pattern.names {
+			naming[name] = groups[i+1]
+		}
+		matches = append(matches, RnMatch{Class: pattern.class, Naming: naming})
+	}
+	return matches
+}
@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/brightpuddle/goaci/meta"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// registerTestMeta registers the metadata test model and returns a function to unregister it.
+func registerTestMeta(t *testing.T) func() {
+	model, err := meta.LoadFile("../meta/testdata/aci-meta.json", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		meta.Unregister(model.Version)
+	}
+}
+
+// TestMatchRn tests the MatchRn function.
+func TestMatchRn(t *testing.T) {
+	// Simple template
+	assert.Equal(t, []RnMatch{{Class: "fvBD", Naming: map[string]string{"name": "web"}}}, MatchRn("BD-web"))
+
+	// Bracketed property, shared by several classes
+	matches := MatchRn("subnet-[10.0.0.1/24]")
+	assert.Contains(t, matches, RnMatch{Class: "fvSubnet", Naming: map[string]string{"ip": "10.0.0.1/24"}})
+	assert.Contains(t, matches, RnMatch{Class: "cloudSubnet", Naming: map[string]string{"ip": "10.0.0.1/24"}})
+
+	// Multiple properties
+	matches = MatchRn("mDev-Fortinet-FGAPIC-1.3")
+	if assert.NotEmpty(t, matches) {
+		assert.Equal(t, "vnsMDev", matches[0].Class)
+		assert.Equal(t, "Fortinet", matches[0].Naming["vendor"])
+	}
+
+	// Constant template ranks above a generic one
+	matches = MatchRn("rsctx")
+	if assert.NotEmpty(t, matches) {
+		assert.Equal(t, "fvRsCtx", matches[0].Class)
+		assert.Empty(t, matches[0].Naming)
+	}
+
+	// No match
+	assert.Empty(t, MatchRn("nonexistent-rn-format"))
+}
+
+// TestMatchDn tests the MatchDn function.
+func TestMatchDn(t *testing.T) {
+	match, err := MatchDn("uni/tn-a/out-x/lnodep-y/rsnodeL3OutAtt-[topology/pod-1/node-101]")
+	assert.NoError(t, err)
+	assert.Equal(t, "l3extRsNodeL3OutAtt", match.Class)
+	assert.Equal(t, "topology/pod-1/node-101", match.Naming["tDn"])
+
+	_, err = MatchDn("uni/nonexistent-rn-format")
+	assert.Error(t, err)
+}
+
+// TestNewRn tests the NewRn function.
+func TestNewRn(t *testing.T) {
+	rn, err := NewRn("fvSubnet", map[string]string{"ip": "10.0.0.1/24"})
+	assert.NoError(t, err)
+	assert.Equal(t, "subnet-[10.0.0.1/24]", rn)
+	_, err = NewRn("FakeTestClass", nil)
+	assert.Error(t, err)
+}
+
+// TestRnClasses tests listing the classes with RN templates.
+func TestRnClasses(t *testing.T) {
+	classes := RnClasses()
+	assert.Contains(t, classes, "fvTenant")
+	assert.NotContains(t, classes, "fvEpMacTag")
+	assert.True(t, sort.StringsAreSorted(classes))
+
+	defer registerTestMeta(t)()
+	assert.Contains(t, RnClasses(), "fvEpMacTag")
+}
+
+// TestMetaRnTemplates tests RN templates from the metadata for classes missing from the RN table.
+func TestMetaRnTemplates(t *testing.T) {
+	tree := gjson.Parse(`{"polUni":{"attributes":{"dn":"uni"},"children":[
+		{"fvTenant":{"attributes":{"name":"a"},"children":[
+			{"fvEpTags":{"children":[
+				{"fvEpMacTag":{"attributes":{"mac":"00:11:22:33:44:55","bdName":"bd"}}}
+			]}}
+		]}}
+	]}}`)
+	dn := "uni/tn-a/eptags/epmactag-00:11:22:33:44:55-[bd]"
+
+	// Without metadata
+	bkup := newClient()
+	bkup.addToDB(tree)
+	_, err := bkup.GetDn(dn)
+	assert.Error(t, err)
+	assert.Empty(t, MatchRn("eptags"))
+	_, err = MatchDn("uni/tn-a/BD-web/subnet-[10.0.0.1/24]")
+	assert.EqualError(t, err, "subnet-[10.0.0.1/24] matches several classes: "+meta.ErrNoModels.Error())
+
+	// With metadata
+	defer registerTestMeta(t)()
+	bkup = newClient()
+	bkup.addToDB(tree)
+	res, err := bkup.GetDn(dn)
+	assert.NoError(t, err)
+	assert.Equal(t, "bd", res.Get("fvEpMacTag.attributes.bdName").Str)
+	match, err := MatchDn(dn)
+	assert.NoError(t, err)
+	assert.Equal(t, RnMatch{Class: "fvEpMacTag", Naming: map[string]string{"mac": "00:11:22:33:44:55", "bdName": "bd"}}, match)
+
+	// Containment rules out classes sharing an RN template
+	match, _ = MatchDn("uni/tn-a/BD-web/subnet-[10.0.0.1/24]")
+	assert.Equal(t, "fvSubnet", match.Class)
+}
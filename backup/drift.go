@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/brightpuddle/goaci/meta"
+)
+
+// Maximum number of classes per target-subtree-class query when checking a scoped subtree.
+const driftClassesPerQuery = 50
+
+// Drift compares a live fabric against a backup, e.g. the last approved configuration.
+// The live fabric is queried for every class in the backup and the result is reported
+// relative to the backup:
+//
+//  Removed:  objects in the backup missing from the fabric
+//  Added:    extra objects on the fabric
+//  Modified: objects with differing attributes
+//
+// The fabric also returns operational attributes, so attributes missing from the backup are
+// compared using the metadata: configurable attributes are compared against their default value,
+// others are left out. This needs the metadata models, see meta.LoadFile; meta.ErrNoModels is
+// returned otherwise. Use the Scope modifier to check a single subtree, e.g. a tenant:
+//  drift, err := backup.Drift(&client, bkup, backup.Scope("uni/tn-mytenant"), backup.IgnoreVolatile)
+func Drift(live *goaci.Client, bkup Client, mods ...func(*DiffOptions)) (DiffResult, error) {
+	if err := meta.Check(); err != nil {
+		return DiffResult{}, err
+	}
+	opts := newDiffOptions(mods...)
+	opts.common = true
+
+	// Classes present in the backup within the scope
+	var classes []string
+	for class, objs := range bkup.Classes {
+		for _, obj := range objs {
+			if opts.inScope(dnOf(*obj)) {
+				classes = append(classes, class)
+				break
+			}
+		}
+	}
+	sort.Strings(classes)
+
+	// Index the live objects as with a backup file
+	liveClient := newClient()
+	if opts.Scope == "" {
+		for _, class := range classes {
+			res, err := live.GetClass(class)
+			if err != nil {
+				return DiffResult{}, err
+			}
+			for _, obj := range res.Array() {
+				liveClient.addToDB(obj)
+			}
+		}
+	} else {
+		for i := 0; i < len(classes); i += driftClassesPerQuery {
+			end := i + driftClassesPerQuery
+			if end > len(classes) {
+				end = len(classes)
+			}
+			res, err := live.Get("/api/mo/"+opts.Scope,
+				goaci.Query("query-target", "subtree"),
+				goaci.Query("target-subtree-class", strings.Join(classes[i:end], ",")),
+			)
+			if err != nil {
+				return DiffResult{}, err
+			}
+			for _, obj := range res.Get("imdata").Array() {
+				liveClient.addToDB(obj)
+			}
+		}
+	}
+
+	return compare(bkup, liveClient, opts), nil
+}
@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/brightpuddle/goaci/meta"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const testURL = "https://10.0.0.1"
+
+// testLiveClient creates a goaci client with mocked HTTP requests.
+func testLiveClient() goaci.Client {
+	client, _ := goaci.NewClient(testURL, "usr", "pwd")
+	client.LastRefresh = time.Now()
+	gock.InterceptClient(client.HttpClient)
+	return client
+}
+
+// TestDrift tests the Drift function.
+func TestDrift(t *testing.T) {
+	defer gock.Off()
+	live := testLiveClient()
+	bkup, _ := testClient()
+
+	// The metadata is needed to compare attributes missing from the backup
+	_, err := Drift(&live, bkup)
+	assert.Equal(t, meta.ErrNoModels, err)
+	defer registerTestMeta(t)()
+
+	// Tenant b is missing, tenant c is extra and tenant a has a descr, which isn't in the backup:
+	// configurable attributes missing from the backup are compared against their default, and
+	// operational attributes such as modTs are left out
+	gock.New(testURL).
+		Get("/api/class/fvTenant.json").
+		Reply(200).
+		BodyString(`{"imdata":[
+			{"fvTenant":{"attributes":{"dn":"uni/tn-a","name":"a","descr":"changed","nameAlias":"","modTs":"now"}}},
+			{"fvTenant":{"attributes":{"dn":"uni/tn-c","name":"c"}}}
+		]}`)
+	gock.New(testURL).
+		Get("/api/class/polUni.json").
+		Reply(200).
+		BodyString(`{"imdata":[{"polUni":{"attributes":{"dn":"uni","name":"root"}}}]}`)
+
+	drift, err := Drift(&live, bkup)
+	assert.NoError(t, err)
+	if assert.Len(t, drift.Removed, 1) {
+		assert.Equal(t, "uni/tn-b", drift.Removed[0].Dn)
+	}
+	if assert.Len(t, drift.Added, 1) {
+		assert.Equal(t, "uni/tn-c", drift.Added[0].Dn)
+	}
+	if assert.Len(t, drift.Modified, 1) {
+		assert.Equal(t, "uni/tn-a", drift.Modified[0].Dn)
+		assert.Equal(t, []AttributeChange{{Name: "descr", Old: "", New: "changed"}}, drift.Modified[0].Changes)
+	}
+
+	// Scoped to a tenant
+	gock.New(testURL).
+		Get("/api/mo/uni/tn-a.json").
+		MatchParam("query-target", "subtree").
+		MatchParam("target-subtree-class", "fvTenant").
+		Reply(200).
+		BodyString(`{"imdata":[{"fvTenant":{"attributes":{"dn":"uni/tn-a","name":"renamed"}}}]}`)
+	drift, err = Drift(&live, bkup, Scope("uni/tn-a"))
+	assert.NoError(t, err)
+	assert.Empty(t, drift.Added)
+	assert.Empty(t, drift.Removed)
+	if assert.Len(t, drift.Modified, 1) {
+		assert.Equal(t, []AttributeChange{{Name: "name", Old: "a", New: "renamed"}}, drift.Modified[0].Changes)
+	}
+
+	// HTTP error
+	gock.New(testURL).Get("/api/class/fvTenant.json").ReplyError(errors.New("fail"))
+	_, err = Drift(&live, bkup)
+	assert.Error(t, err)
+}
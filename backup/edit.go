@@ -0,0 +1,142 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// derivedDn returns the DN of an object as derived from its parent DN and naming properties.
+func derivedDn(parentDn, class string, attrs Res) (string, bool) {
+	template, ok := rnTemplate(class)
+	if !ok {
+		return "", false
+	}
+	rn := fmtRn(template, attrs)
+	if parentDn == "" {
+		return rn, true
+	}
+	return parentDn + "/" + rn, true
+}
+
+// move changes the DN of an object and its subtree.
+func (client Client) move(dn, newDn string) {
+	objs := append([]*Res{client.DNs[dn]}, client.subtree(dn)...)
+	for _, obj := range objs {
+		oldDn := dnOf(*obj)
+		thisDn := newDn + strings.TrimPrefix(oldDn, dn)
+		*obj = Body{Str: obj.Raw}.Set(className(*obj)+".attributes.dn", thisDn).Res()
+		delete(client.DNs, oldDn)
+		client.DNs[thisDn] = obj
+		if children, ok := client.Children[oldDn]; ok {
+			delete(client.Children, oldDn)
+			client.Children[thisDn] = children
+		}
+	}
+}
+
+// SetAttributes sets attributes on an object in the backup.
+// Changing a naming property renames the object, i.e. changes the DN of the object and its subtree, e.g.
+//  bkup.SetAttributes("uni/tn-old", map[string]string{"name": "new", "descr": "Renamed"})
+// moves uni/tn-old and its children to uni/tn-new.
+func (client Client) SetAttributes(dn string, attrs map[string]string) error {
+	obj, ok := client.DNs[dn]
+	if !ok {
+		return fmt.Errorf("%s not found", dn)
+	}
+	if _, ok := attrs["dn"]; ok {
+		return errors.New("dn cannot be set directly; set the naming properties instead")
+	}
+	class := className(*obj)
+	parentDn := parentOf(dn)
+	oldDn, derived := derivedDn(parentDn, class, obj.Get(class+".attributes"))
+
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	body := Body{Str: obj.Raw}
+	for _, name := range names {
+		body = body.Set(class+".attributes."+name, attrs[name])
+	}
+	updated := body.Res()
+
+	// Rename if the DN follows from the naming properties
+	if derived && oldDn == dn {
+		newDn, _ := derivedDn(parentDn, class, updated.Get(class+".attributes"))
+		if newDn != dn {
+			if _, exists := client.DNs[newDn]; exists {
+				return fmt.Errorf("%s already exists", newDn)
+			}
+			*obj = updated
+			client.move(dn, newDn)
+			return nil
+		}
+	}
+	*obj = updated
+	return nil
+}
+
+// Add adds an object and its children under a parent DN.
+// The object is in the backup file format, e.g.
+//  bkup.Add("uni/tn-a", backup.Body{}.Set("fvBD.attributes.name", "bd").Res())
+// Children may be nested under the children key as in the backup file.
+func (client Client) Add(parentDn string, obj Res) error {
+	if _, ok := client.DNs[parentDn]; !ok && parentDn != "" {
+		return fmt.Errorf("%s not found", parentDn)
+	}
+	class := className(obj)
+	if class == "" {
+		return errors.New("object has no class")
+	}
+	rns, err := buildDn(obj.Get(class+".attributes"), splitDn(parentDn), class)
+	if err != nil {
+		return err
+	}
+	dn := strings.Join(rns, "/")
+	if parentOf(dn) != parentDn {
+		return fmt.Errorf("%s is not a child of %s", dn, parentDn)
+	}
+	if _, exists := client.DNs[dn]; exists {
+		return fmt.Errorf("%s already exists", dn)
+	}
+	client.addTree(obj, splitDn(parentDn))
+	return nil
+}
+
+// Remove removes an object and its subtree from the backup.
+func (client Client) Remove(dn string) error {
+	obj, ok := client.DNs[dn]
+	if !ok {
+		return fmt.Errorf("%s not found", dn)
+	}
+	removed := make(map[*Res]bool)
+	for _, o := range append([]*Res{obj}, client.subtree(dn)...) {
+		removed[o] = true
+		delete(client.DNs, dnOf(*o))
+		delete(client.Children, dnOf(*o))
+	}
+
+	// Remove the objects from the class and parent indexes
+	without := func(objs []*Res) []*Res {
+		var kept []*Res
+		for _, o := range objs {
+			if !removed[o] {
+				kept = append(kept, o)
+			}
+		}
+		return kept
+	}
+	for class, objs := range client.Classes {
+		if kept := without(objs); len(kept) > 0 {
+			client.Classes[class] = kept
+		} else {
+			delete(client.Classes, class)
+		}
+	}
+	parentDn := parentOf(dn)
+	client.Children[parentDn] = without(client.Children[parentDn])
+	return nil
+}
@@ -0,0 +1,100 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// newTestEditClient creates an in-memory backup for edit tests.
+func newTestEditClient() Client {
+	bkup := newClient()
+	bkup.addToDB(gjson.Parse(`{"polUni":{"attributes":{"dn":"uni"},"children":[
+		{"fvTenant":{"attributes":{"name":"a"},"children":[
+			{"fvBD":{"attributes":{"name":"bd"},"children":[
+				{"fvSubnet":{"attributes":{"ip":"10.0.0.1/24"}}}
+			]}}
+		]}},
+		{"fvTenant":{"attributes":{"name":"b"}}}
+	]}}`))
+	return bkup
+}
+
+// TestClientSetAttributes tests the Client::SetAttributes method.
+func TestClientSetAttributes(t *testing.T) {
+	bkup := newTestEditClient()
+
+	// Non-naming attribute
+	assert.NoError(t, bkup.SetAttributes("uni/tn-a", map[string]string{"descr": "changed"}))
+	res, _ := bkup.GetDn("uni/tn-a")
+	assert.Equal(t, "changed", res.Get("fvTenant.attributes.descr").Str)
+
+	// Rename moves the subtree
+	assert.NoError(t, bkup.SetAttributes("uni/tn-a", map[string]string{"name": "renamed"}))
+	_, err := bkup.GetDn("uni/tn-a")
+	assert.Error(t, err)
+	res, _ = bkup.GetDn("uni/tn-renamed/BD-bd/subnet-[10.0.0.1/24]")
+	assert.Equal(t, "uni/tn-renamed/BD-bd/subnet-[10.0.0.1/24]", res.Get("fvSubnet.attributes.dn").Str)
+	res, _ = bkup.GetClass("fvTenant", goaci.Query("query-target-filter", `eq(fvTenant.name,"renamed")`))
+	assert.Equal(t, "changed", res.Get("0.fvTenant.attributes.descr").Str)
+	res, _ = bkup.GetDn("uni/tn-renamed", goaci.Query("query-target", "children"))
+	assert.Len(t, res.Array(), 1)
+
+	// Rename to an existing DN
+	assert.Error(t, bkup.SetAttributes("uni/tn-renamed", map[string]string{"name": "b"}))
+
+	// Setting the DN
+	assert.Error(t, bkup.SetAttributes("uni/tn-b", map[string]string{"dn": "uni/tn-c"}))
+
+	// Missing DN
+	assert.Error(t, bkup.SetAttributes("uni/tn-missing", map[string]string{}))
+}
+
+// TestClientAdd tests the Client::Add method.
+func TestClientAdd(t *testing.T) {
+	bkup := newTestEditClient()
+
+	ctx := gjson.Parse(`{"fvCtx":{"attributes":{"name":"vrf"},"children":[
+		{"fvRsCtxToEpRet":{"attributes":{"tnFvEpRetPolName":""}}}
+	]}}`)
+	assert.NoError(t, bkup.Add("uni/tn-b", ctx))
+	res, err := bkup.GetDn("uni/tn-b/ctx-vrf/rsctxToEpRet")
+	assert.NoError(t, err)
+	assert.True(t, res.Exists())
+	res, _ = bkup.GetDn("uni/tn-b", goaci.Query("query-target", "children"))
+	assert.Len(t, res.Array(), 1)
+
+	// Duplicate
+	assert.Error(t, bkup.Add("uni/tn-b", ctx))
+
+	// Missing parent
+	assert.Error(t, bkup.Add("uni/tn-missing", ctx))
+
+	// DN outside of the parent
+	assert.Error(t, bkup.Add("uni/tn-a", Body{}.Set("fvCtx.attributes.dn", "uni/tn-b/ctx-x").Res()))
+
+	// Unknown class
+	assert.Error(t, bkup.Add("uni/tn-a", Body{}.Set("fakeClass.attributes.name", "x").Res()))
+}
+
+// TestClientRemove tests the Client::Remove method.
+func TestClientRemove(t *testing.T) {
+	bkup := newTestEditClient()
+
+	assert.NoError(t, bkup.Remove("uni/tn-a"))
+	for _, dn := range []string{"uni/tn-a", "uni/tn-a/BD-bd", "uni/tn-a/BD-bd/subnet-[10.0.0.1/24]"} {
+		_, err := bkup.GetDn(dn)
+		assert.Error(t, err, dn)
+	}
+	_, err := bkup.GetClass("fvBD")
+	assert.Error(t, err)
+	res, _ := bkup.GetClass("fvTenant")
+	assert.Len(t, res.Array(), 1)
+	res, _ = bkup.GetDn("uni", goaci.Query("query-target", "children"))
+	assert.Len(t, res.Array(), 1)
+
+	// Missing DN
+	assert.Error(t, bkup.Remove("uni/tn-a"))
+}
@@ -0,0 +1,252 @@
+package backup
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Filter is a parsed APIC filter expression, i.e. the value of the
+// query-target-filter query parameter, e.g.
+//  and(eq(fvBD.name,"bd1"),ne(fvBD.unkMacUcastAct,"flood"))
+// Use ParseFilter to create a Filter.
+type Filter struct {
+	op       string
+	class    string
+	prop     string
+	values   []string
+	operands []Filter
+}
+
+// Number of value arguments expected by each property operator.
+var filterOps = map[string]int{
+	"eq":    1,
+	"ne":    1,
+	"lt":    1,
+	"gt":    1,
+	"le":    1,
+	"ge":    1,
+	"bw":    2,
+	"wcard": 1,
+}
+
+// ParseFilter parses an APIC filter expression.
+// Supported operators are eq, ne, lt, gt, le, ge, bw, wcard, and, or and not.
+func ParseFilter(expr string) (Filter, error) {
+	p := filterParser{src: expr}
+	filter, err := p.parseExpr()
+	if err != nil {
+		return Filter{}, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return Filter{}, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return filter, nil
+}
+
+// Match reports whether an object matches the filter.
+// The object is in the format returned by the client, e.g.
+//  { "fvBD": { "attributes": { ... } } }
+// Property conditions only match objects of the class named in the condition.
+func (filter Filter) Match(obj Res) bool {
+	class := className(obj)
+	return filter.match(class, obj.Get(class+".attributes"))
+}
+
+func (filter Filter) match(class string, attrs Res) bool {
+	switch filter.op {
+	case "and":
+		for _, operand := range filter.operands {
+			if !operand.match(class, attrs) {
+				return false
+			}
+		}
+		return true
+	case "or":
+		for _, operand := range filter.operands {
+			if operand.match(class, attrs) {
+				return true
+			}
+		}
+		return false
+	case "not":
+		return !filter.operands[0].match(class, attrs)
+	}
+
+	if filter.class != class {
+		return false
+	}
+	value := attrs.Get(filter.prop)
+	if !value.Exists() {
+		return false
+	}
+	v := value.String()
+	switch filter.op {
+	case "eq":
+		return compareValues(v, filter.values[0]) == 0
+	case "ne":
+		return compareValues(v, filter.values[0]) != 0
+	case "lt":
+		return compareValues(v, filter.values[0]) < 0
+	case "gt":
+		return compareValues(v, filter.values[0]) > 0
+	case "le":
+		return compareValues(v, filter.values[0]) <= 0
+	case "ge":
+		return compareValues(v, filter.values[0]) >= 0
+	case "bw":
+		return compareValues(v, filter.values[0]) >= 0 &&
+			compareValues(v, filter.values[1]) <= 0
+	case "wcard":
+		re, err := regexp.Compile(filter.values[0])
+		if err != nil {
+			return strings.Contains(v, filter.values[0])
+		}
+		return re.MatchString(v)
+	}
+	return false
+}
+
+// compareValues compares two property values.
+// As with the APIC, values are compared numerically when both are numbers,
+// e.g. "9" is less than "10", and lexically otherwise.
+func compareValues(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// filterParser is a recursive descent parser for filter expressions.
+type filterParser struct {
+	src string
+	pos int
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid filter at position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *filterParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// ident reads an operator name or class.property reference.
+func (p *filterParser) ident() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '(' || c == ')' || c == ',' || c == '"' || strings.ContainsRune(" \t\r\n", rune(c)) {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// quoted reads a double quoted string value.
+func (p *filterParser) quoted() (string, error) {
+	if err := p.expect('"'); err != nil {
+		return "", err
+	}
+	var value strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && p.pos < len(p.src):
+			value.WriteByte(p.src[p.pos])
+			p.pos++
+		case c == '"':
+			return value.String(), nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *filterParser) parseExpr() (Filter, error) {
+	op := p.ident()
+	if op == "" {
+		return Filter{}, p.errorf("expected operator")
+	}
+	if err := p.expect('('); err != nil {
+		return Filter{}, err
+	}
+	filter := Filter{op: op}
+
+	switch op {
+	case "and", "or", "not":
+		for {
+			operand, err := p.parseExpr()
+			if err != nil {
+				return Filter{}, err
+			}
+			filter.operands = append(filter.operands, operand)
+			if p.peek() != ',' {
+				break
+			}
+			p.pos++
+		}
+		if op == "not" && len(filter.operands) != 1 {
+			return Filter{}, p.errorf("not takes a single operand")
+		}
+	default:
+		nValues, ok := filterOps[op]
+		if !ok {
+			return Filter{}, p.errorf("unknown operator %s", op)
+		}
+		ref := p.ident()
+		i := strings.Index(ref, ".")
+		if i < 1 || i == len(ref)-1 {
+			return Filter{}, p.errorf("expected class.property, got %q", ref)
+		}
+		filter.class, filter.prop = ref[:i], ref[i+1:]
+		for n := 0; n < nValues; n++ {
+			if err := p.expect(','); err != nil {
+				return Filter{}, err
+			}
+			value, err := p.quoted()
+			if err != nil {
+				return Filter{}, err
+			}
+			filter.values = append(filter.values, value)
+		}
+	}
+
+	if err := p.expect(')'); err != nil {
+		return Filter{}, err
+	}
+	return filter, nil
+}
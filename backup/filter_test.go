@@ -0,0 +1,72 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseFilter tests the ParseFilter function.
+func TestParseFilter(t *testing.T) {
+	// Valid expressions
+	for _, expr := range []string{
+		`eq(fvBD.name,"a")`,
+		`and(eq(fvBD.name,"a"), ne(fvBD.descr,"b"))`,
+		`or(wcard(fvBD.name,"^a"),bw(fvBD.seg,"1","10"),not(gt(fvBD.mtu,"9000")))`,
+		`eq(fvBD.descr,"quoted \"value\"")`,
+	} {
+		_, err := ParseFilter(expr)
+		assert.NoError(t, err, expr)
+	}
+
+	// Invalid expressions
+	for _, expr := range []string{
+		``,
+		`eq(fvBD.name)`,
+		`eq(name,"a")`,
+		`foo(fvBD.name,"a")`,
+		`eq(fvBD.name,"a"`,
+		`eq(fvBD.name,"a") extra`,
+		`not(eq(fvBD.name,"a"),eq(fvBD.name,"b"))`,
+		`eq(fvBD.name,"unterminated)`,
+	} {
+		_, err := ParseFilter(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+// TestFilterMatch tests the Filter::Match method.
+func TestFilterMatch(t *testing.T) {
+	bd := Body{}.
+		Set("fvBD.attributes.name", "bd-web").
+		Set("fvBD.attributes.mtu", "9000").
+		Set("fvBD.attributes.seg", "16000001").
+		Res()
+
+	tests := map[string]bool{
+		`eq(fvBD.name,"bd-web")`:                          true,
+		`eq(fvBD.name,"bd-app")`:                          false,
+		`eq(fvCtx.name,"bd-web")`:                         false,
+		`eq(fvBD.missing,"")`:                             false,
+		`ne(fvBD.name,"bd-app")`:                          true,
+		`gt(fvBD.mtu,"1500")`:                             true,
+		`lt(fvBD.mtu,"10000")`:                            true,
+		`ge(fvBD.mtu,"9000")`:                             true,
+		`le(fvBD.mtu,"8999")`:                             false,
+		`lt(fvBD.name,"bd-x")`:                            true,
+		`bw(fvBD.seg,"16000000","16000010")`:              true,
+		`wcard(fvBD.name,"web")`:                          true,
+		`wcard(fvBD.name,"^app")`:                         false,
+		`wcard(fvBD.name,"[")`:                            false,
+		`and(eq(fvBD.name,"bd-web"),eq(fvBD.mtu,"9000"))`: true,
+		`and(eq(fvBD.name,"bd-web"),eq(fvBD.mtu,"1500"))`: false,
+		`or(eq(fvBD.name,"bd-app"),eq(fvBD.mtu,"9000"))`:  true,
+		`not(eq(fvBD.name,"bd-web"))`:                     false,
+	}
+	for expr, expected := range tests {
+		filter, err := ParseFilter(expr)
+		if assert.NoError(t, err, expr) {
+			assert.Equal(t, expected, filter.Match(bd), expr)
+		}
+	}
+}
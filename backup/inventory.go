@@ -0,0 +1,185 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Allocation is a resource identifier allocated to an object, as recorded in the
+// idconfig/*_idfile.xml files of a backup, e.g. the pcTag of an EPG or the segment ID (VNID) of a BD.
+type Allocation struct {
+	// Dn is the DN of the object the identifier is allocated to.
+	Dn string `json:"dn"`
+	// Class is the class of the object, e.g. fvAEPg.
+	Class string `json:"class"`
+	// Type is the identifier type, e.g. pcTag or seg.
+	// The pcTag of a VRF has the type class.
+	Type string `json:"type"`
+	// Scope is the namespace of the identifier, e.g. the VRF segment ID for a pcTag.
+	Scope string `json:"scope"`
+	// ID is the allocated identifier.
+	ID string `json:"id"`
+}
+
+// Node is a fabric node from the dhcpconfig files of a backup.
+type Node struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Serial   string `json:"serial"`
+	Model    string `json:"model"`
+	Role     string `json:"role"`
+	Version  string `json:"version"`
+	PodID    string `json:"podId"`
+	FabricID string `json:"fabricId"`
+	IP       string `json:"ip"`
+	// State is the DHCP client state, e.g. assigned.
+	State string `json:"state"`
+}
+
+// descendants returns the descendants of an object in the backup file format of the given classes.
+func descendants(obj Res, classes ...string) (found []Res) {
+	for _, child := range obj.Get(className(obj) + ".children").Array() {
+		class := className(child)
+		for _, c := range classes {
+			if c == class {
+				found = append(found, child)
+				break
+			}
+		}
+		found = append(found, descendants(child, classes...)...)
+	}
+	return found
+}
+
+// parseIdFile reads the allocations from an idconfig file.
+func parseIdFile(root Res) (allocs []Allocation) {
+	for _, subj := range descendants(root, "resSubj") {
+		oDn := subj.Get("resSubj.attributes.oDn").Str
+		oCl := subj.Get("resSubj.attributes.oCl").Str
+		for _, child := range subj.Get("resSubj.children").Array() {
+			var typ, scope string
+			switch className(child) {
+			case "identConsumer":
+				typ = child.Get("identConsumer.attributes.subj").Str
+				scope = child.Get("identConsumer.attributes.ns").Str
+			case "resReqCtx":
+				typ = child.Get("resReqCtx.attributes.ctxType").Str
+				scope = child.Get("resReqCtx.attributes.ctx").Str
+			default:
+				continue
+			}
+			for _, inst := range descendants(child, "identInst16", "identInst32") {
+				allocs = append(allocs, Allocation{
+					Dn:    oDn,
+					Class: oCl,
+					Type:  typ,
+					Scope: scope,
+					ID:    inst.Get(className(inst) + ".attributes.id").Str,
+				})
+			}
+		}
+	}
+	return allocs
+}
+
+// parseDhcpFile reads the fabric nodes from a dhcpconfig file.
+func parseDhcpFile(root Res) (nodes []Node) {
+	for _, obj := range descendants(root, "dhcpClient") {
+		attrs := obj.Get("dhcpClient.attributes")
+		nodes = append(nodes, Node{
+			ID:       attrs.Get("nodeId").Str,
+			Name:     attrs.Get("name").Str,
+			Serial:   attrs.Get("id").Str,
+			Model:    attrs.Get("model").Str,
+			Role:     attrs.Get("nodeRole").Str,
+			Version:  attrs.Get("runningVer").Str,
+			PodID:    attrs.Get("podId").Str,
+			FabricID: attrs.Get("fabricId").Str,
+			IP:       attrs.Get("ip").Str,
+			State:    attrs.Get("clientEvent").Str,
+		})
+	}
+	return nodes
+}
+
+// loadInventory parses the idconfig and dhcpconfig files of the backup.
+// Files that can't be parsed are skipped with a warning.
+func (client *Client) loadInventory() {
+	names := make([]string, 0, len(client.Files))
+	for name := range client.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data := client.Files[name]
+		isId := strings.HasPrefix(name, "idconfig/")
+		isDhcp := strings.HasPrefix(name, "dhcpconfig/")
+		if !strings.HasSuffix(name, ".xml") || !(isId || isDhcp) {
+			continue
+		}
+		root, err := xmlToJSON(bytes.NewReader(data))
+		if err != nil {
+			client.Warnings = append(client.Warnings, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if isId {
+			for _, alloc := range parseIdFile(root) {
+				client.Allocations[alloc.Dn] = append(client.Allocations[alloc.Dn], alloc)
+			}
+		} else {
+			client.Nodes = append(client.Nodes, parseDhcpFile(root)...)
+		}
+	}
+	sort.SliceStable(client.Nodes, func(i, j int) bool {
+		a, _ := strconv.Atoi(client.Nodes[i].ID)
+		b, _ := strconv.Atoi(client.Nodes[j].ID)
+		return a < b
+	})
+}
+
+// allocation returns the identifier of the given types allocated to an object.
+// Globally scoped identifiers are preferred, e.g. the global pcTag of an EPG providing shared services.
+func (client Client) allocation(dn string, types ...string) (string, error) {
+	for _, typ := range types {
+		var found []Allocation
+		for _, alloc := range client.Allocations[dn] {
+			if alloc.Type == typ {
+				found = append(found, alloc)
+			}
+		}
+		for _, alloc := range found {
+			if alloc.Scope == "Shared" {
+				return alloc.ID, nil
+			}
+		}
+		if len(found) > 0 {
+			return found[0].ID, nil
+		}
+	}
+	return "", fmt.Errorf("no %s allocation found for %s", strings.Join(types, "/"), dn)
+}
+
+// PcTag returns the pcTag allocated to an object, e.g. an EPG, BD or VRF.
+// An object may have several pcTag allocations; the global pcTag is returned if the
+// object has one. See Allocations for the full list.
+func (client Client) PcTag(dn string) (string, error) {
+	return client.allocation(dn, "pcTag", "class")
+}
+
+// Segment returns the segment ID (VNID) allocated to an object, e.g. a BD or VRF.
+func (client Client) Segment(dn string) (string, error) {
+	return client.allocation(dn, "seg")
+}
+
+// Node returns a fabric node by node ID.
+func (client Client) Node(id string) (Node, error) {
+	for _, node := range client.Nodes {
+		if node.ID == id {
+			return node, nil
+		}
+	}
+	return Node{}, fmt.Errorf("node %s not found", id)
+}
@@ -0,0 +1,117 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testIdFile = `<?xml version="1.0"?>
+<topRoot dn="">
+  <resCont dn="">
+    <resSubj dn="" oCl="fvCtx" oDn="uni/tn-a/ctx-vrf">
+      <identConsumer dn="" ns="2523142" subj="class">
+        <identSource dn="" shard="1">
+          <identInst16 dn="" elementDn="idm/runi-class-2523142/id16-32770" id="32770"/>
+        </identSource>
+      </identConsumer>
+      <identConsumer dn="" ns="ctx" subj="seg">
+        <identSource dn="" shard="1">
+          <identInst32 dn="" elementDn="idm/suni-seg-ctx/id32-2523142" id="2523142"/>
+        </identSource>
+      </identConsumer>
+    </resSubj>
+    <resSubj dn="" oCl="fvAEPg" oDn="uni/tn-a/ap-app/epg-web">
+      <resReqCtx ctx="2523142" ctxType="pcTag" dn="">
+        <identConsumer dn="" ns="2523142" subj="class">
+          <identSource dn="" shard="1">
+            <identInst16 dn="" elementDn="idm/runi-class-2523142/id16-49173" id="49173"/>
+          </identSource>
+        </identConsumer>
+        <resCtx dn="" oDn="uni/epp/fv-[uni/tn-a/ap-app/epg-web]/node-101"/>
+      </resReqCtx>
+    </resSubj>
+    <resSubj dn="" oCl="fvAEPg" oDn="uni/tn-a/ap-app/epg-shared">
+      <resReqCtx ctx="2523142" ctxType="pcTag" dn="">
+        <identConsumer dn="" ns="2523142" subj="class">
+          <identInst16 dn="" id="49174"/>
+        </identConsumer>
+      </resReqCtx>
+      <resReqCtx ctx="Shared" ctxType="pcTag" dn="">
+        <identConsumer dn="" ns="Shared" subj="class">
+          <identInst16 dn="" id="5480"/>
+        </identConsumer>
+      </resReqCtx>
+    </resSubj>
+  </resCont>
+</topRoot>`
+
+const testDhcpFile = `<?xml version="1.0"?>
+<topRoot dn="">
+  <dhcpClient clientEvent="assigned" dn="" fabricId="1" id="FDO2" ip="10.0.0.2/32" model="N9K-C9364C" name="spine-201" nodeId="201" nodeRole="spine" podId="1" runningVer="n9000-14.2(3l)"/>
+  <dhcpClient clientEvent="assigned" dn="" fabricId="1" id="FDO1" ip="10.0.0.1/32" model="N9K-C93108TC-EX" name="leaf-101" nodeId="101" nodeRole="leaf" podId="1" runningVer="n9000-14.2(3l)"/>
+</topRoot>`
+
+// TestClientInventory tests the loading of idconfig and dhcpconfig files.
+func TestClientInventory(t *testing.T) {
+	bkup, _ := testClient()
+	bkup = roundTrip(t, bkup, FormatJSON)
+	bkup.Files["idconfig/config_1_idfile.xml"] = []byte(testIdFile)
+	bkup.Files["dhcpconfig/config_255_idfile.xml"] = []byte(testDhcpFile)
+	bkup = roundTrip(t, bkup, FormatJSON)
+
+	// Allocations
+	pcTag, err := bkup.PcTag("uni/tn-a/ap-app/epg-web")
+	assert.NoError(t, err)
+	assert.Equal(t, "49173", pcTag)
+	pcTag, _ = bkup.PcTag("uni/tn-a/ap-app/epg-shared")
+	assert.Equal(t, "5480", pcTag)
+	pcTag, _ = bkup.PcTag("uni/tn-a/ctx-vrf")
+	assert.Equal(t, "32770", pcTag)
+	seg, _ := bkup.Segment("uni/tn-a/ctx-vrf")
+	assert.Equal(t, "2523142", seg)
+	_, err = bkup.Segment("uni/tn-a/ap-app/epg-web")
+	assert.Error(t, err)
+	assert.Equal(t, []Allocation{{
+		Dn:    "uni/tn-a/ap-app/epg-web",
+		Class: "fvAEPg",
+		Type:  "pcTag",
+		Scope: "2523142",
+		ID:    "49173",
+	}}, bkup.Allocations["uni/tn-a/ap-app/epg-web"])
+
+	// Nodes
+	if assert.Len(t, bkup.Nodes, 2) {
+		assert.Equal(t, "101", bkup.Nodes[0].ID)
+	}
+	node, err := bkup.Node("201")
+	assert.NoError(t, err)
+	assert.Equal(t, Node{
+		ID:       "201",
+		Name:     "spine-201",
+		Serial:   "FDO2",
+		Model:    "N9K-C9364C",
+		Role:     "spine",
+		Version:  "n9000-14.2(3l)",
+		PodID:    "1",
+		FabricID: "1",
+		IP:       "10.0.0.2/32",
+		State:    "assigned",
+	}, node)
+	_, err = bkup.Node("999")
+	assert.Error(t, err)
+
+	// Invalid metadata files are skipped with a warning
+	bkup.Files["idconfig/invalid_idfile.xml"] = []byte("<topRoot>")
+	dst, cleanup := writeTemp(t, bkup, FormatJSON)
+	defer cleanup()
+	loaded, err := NewClient(dst)
+	assert.NoError(t, err)
+	if assert.Len(t, loaded.Warnings, 1) {
+		assert.Contains(t, loaded.Warnings[0], "idconfig/invalid_idfile.xml: ")
+	}
+	pcTag, _ = loaded.PcTag("uni/tn-a/ap-app/epg-web")
+	assert.Equal(t, "49173", pcTag)
+	res, _ := loaded.GetDn("uni/tn-a")
+	assert.Equal(t, "a", res.Get("fvTenant.attributes.name").Str)
+}
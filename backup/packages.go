@@ -0,0 +1,170 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Param is a parameter in a device package schema.
+type Param struct {
+	Key         string `xml:"key,attr" json:"key"`
+	Label       string `xml:"dispLabel,attr" json:"label,omitempty"`
+	Description string `xml:"description,attr" json:"description,omitempty"`
+	// Type is the parameter data type, e.g. str or int.
+	Type       string `xml:"dType,attr" json:"type,omitempty"`
+	Validation string `xml:"validation,attr" json:"validation,omitempty"`
+	Mandatory  bool   `xml:"mandatory,attr" json:"mandatory"`
+}
+
+// Folder is a group of parameters in a device package schema.
+// Folders may be nested.
+type Folder struct {
+	Key         string `xml:"key,attr" json:"key"`
+	Label       string `xml:"dispLabel,attr" json:"label,omitempty"`
+	Description string `xml:"description,attr" json:"description,omitempty"`
+	// Cardinality is n if the folder can be repeated.
+	Cardinality string   `xml:"cardinality,attr" json:"cardinality,omitempty"`
+	Folders     []Folder `xml:"vnsMFolder" json:"folders,omitempty"`
+	Params      []Param  `xml:"vnsMParam" json:"params,omitempty"`
+}
+
+// Function is a service function provided by a device package, e.g. Firewall.
+type Function struct {
+	Name    string   `xml:"name,attr" json:"name"`
+	Folders []Folder `xml:"vnsMFolder" json:"folders,omitempty"`
+}
+
+// Package is an L4-L7 device package included in a backup, e.g. packages/Fortinet.FGAPIC.1.3-51.zip.
+// The package details are read from the DeviceModel.xml file in the package.
+type Package struct {
+	// File is the archive path of the package in the backup.
+	File    string `json:"file"`
+	Vendor  string `xml:"vendor,attr" json:"vendor"`
+	Model   string `xml:"model,attr" json:"model"`
+	Version string `xml:"version,attr" json:"version"`
+	// FuncMask is the supported function types, e.g. GoTo,GoThrough.
+	FuncMask string `xml:"funcMask,attr" json:"funcMask,omitempty"`
+	// DeviceConfig is the device level parameter schema.
+	DeviceConfig []Folder `xml:"vnsMDevCfg>vnsMFolder" json:"deviceConfig,omitempty"`
+	// GroupConfig is the shared parameter schema.
+	GroupConfig []Folder   `xml:"vnsMGrpCfg>vnsMFolder" json:"groupConfig,omitempty"`
+	Functions   []Function `xml:"vnsMFunc" json:"functions,omitempty"`
+}
+
+// Dn is the DN of the vnsMDev object for the package in the configuration.
+func (pkg Package) Dn() string {
+	return fmt.Sprintf("uni/infra/mDev-%s-%s-%s", pkg.Vendor, pkg.Model, pkg.Version)
+}
+
+// parsePackage reads the device model from a device package zip file.
+func parsePackage(name string, data []byte) (Package, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Package{}, err
+	}
+	for _, f := range zr.File {
+		if path.Base(f.Name) != "DeviceModel.xml" {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return Package{}, err
+		}
+		defer r.Close()
+
+		// Vendor device models aren't always well-formed, e.g. unquoted attribute values
+		decoder := xml.NewDecoder(r)
+		decoder.Strict = false
+		model := struct {
+			Package Package `xml:"infraInfra>vnsMDev"`
+		}{}
+		if err := decoder.Decode(&model); err != nil {
+			return Package{}, err
+		}
+		model.Package.File = name
+		return model.Package, nil
+	}
+	return Package{}, fmt.Errorf("DeviceModel.xml not found")
+}
+
+// loadPackages reads the device packages in the backup.
+// Packages that can't be parsed are skipped with a warning.
+func (client *Client) loadPackages() {
+	var names []string
+	for name := range client.Files {
+		if strings.HasPrefix(name, "packages/") && strings.HasSuffix(name, ".zip") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pkg, err := parsePackage(name, client.Files[name])
+		if err != nil {
+			client.Warnings = append(client.Warnings, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		client.Packages = append(client.Packages, pkg)
+	}
+}
+
+// PackageDependents are the objects in the configuration that depend on a device package.
+type PackageDependents struct {
+	// Devices are the DNs of the L4-L7 devices (vnsLDevVip) using the package.
+	Devices []string `json:"devices"`
+	// Graphs are the DNs of the service graph templates (vnsAbsGraph) using the package,
+	// either through a device or a function.
+	Graphs []string `json:"graphs"`
+}
+
+// relationSources returns the DNs of the parents of relation objects of a class
+// with a target DN matching the predicate.
+func (client Client) relationSources(class string, match func(tDn string) bool) (dns []string) {
+	for _, obj := range client.Classes[class] {
+		if match(obj.Get(class + ".attributes.tDn").Str) {
+			dns = append(dns, parentOf(dnOf(*obj)))
+		}
+	}
+	return dns
+}
+
+// Dependents returns the devices and service graphs in the configuration that depend on a device package, e.g.
+//  for _, pkg := range client.Packages {
+//    deps := client.Dependents(pkg)
+//    fmt.Println(pkg.Vendor, pkg.Model, pkg.Version, deps.Graphs)
+//  }
+func (client Client) Dependents(pkg Package) PackageDependents {
+	mDev := pkg.Dn()
+	devices := client.relationSources("vnsRsMDevAtt", func(tDn string) bool {
+		return tDn == mDev
+	})
+	isDevice := make(map[string]bool)
+	for _, dn := range devices {
+		isDevice[dn] = true
+	}
+
+	// Service graph nodes referencing a device or function of the package
+	nodes := client.relationSources("vnsRsNodeToLDev", func(tDn string) bool {
+		return isDevice[tDn]
+	})
+	nodes = append(nodes, client.relationSources("vnsRsNodeToMFunc", func(tDn string) bool {
+		return strings.HasPrefix(tDn, mDev+"/")
+	})...)
+	seen := make(map[string]bool)
+	var graphs []string
+	for _, node := range nodes {
+		graph := parentOf(node)
+		if !seen[graph] {
+			seen[graph] = true
+			graphs = append(graphs, graph)
+		}
+	}
+
+	sort.Strings(devices)
+	sort.Strings(graphs)
+	return PackageDependents{Devices: devices, Graphs: graphs}
+}
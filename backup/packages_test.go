@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// Device models in the wild have unquoted attribute values, so this isn't well-formed.
+const testDeviceModel = `<?xml version="1.0"?>
+<polUni>
+  <infraInfra>
+    <vnsMDev vendor="Acme" model="FW" version="1.0" funcMask="GoTo">
+      <vnsMDevCfg name="DeviceConfig">
+        <vnsMFolder key="HostConfig" dispLabel="Host Configuration">
+          <vnsMParam key="HostName" dispLabel="Host Name" dType="str" mandatory="true"/>
+        </vnsMFolder>
+      </vnsMDevCfg>
+      <vnsMFunc name="Firewall">
+        <vnsMFolder key="Network" cardinality="n">
+          <vnsMFolder key="Route">
+            <vnsMParam key="Distance" dType="int" validation="isDistance"/>
+          </vnsMFolder>
+        </vnsMFolder>
+        <vnsAbsParam key="Distance" value=10/>
+      </vnsMFunc>
+    </vnsMDev>
+  </infraInfra>
+</polUni>`
+
+// testPackage creates a device package zip file.
+func testPackage(t *testing.T, files map[string]string) []byte {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		w.Write([]byte(content))
+	}
+	assert.NoError(t, zw.Close())
+	return b.Bytes()
+}
+
+// TestClientPackages tests loading device packages and the Client::Dependents method.
+func TestClientPackages(t *testing.T) {
+	bkup := newClient()
+	bkup.addToDB(gjson.Parse(`{"polUni":{"attributes":{"dn":"uni"},"children":[
+		{"fvTenant":{"attributes":{"name":"a"},"children":[
+			{"vnsLDevVip":{"attributes":{"name":"fw"},"children":[
+				{"vnsRsMDevAtt":{"attributes":{"tDn":"uni/infra/mDev-Acme-FW-1.0"}}}
+			]}},
+			{"vnsLDevVip":{"attributes":{"name":"other"},"children":[
+				{"vnsRsMDevAtt":{"attributes":{"tDn":"uni/infra/mDev-Other-LB-2.0"}}}
+			]}},
+			{"vnsAbsGraph":{"attributes":{"name":"by-device"},"children":[
+				{"vnsAbsNode":{"attributes":{"name":"N1"},"children":[
+					{"vnsRsNodeToLDev":{"attributes":{"tDn":"uni/tn-a/lDevVip-fw"}}}
+				]}}
+			]}},
+			{"vnsAbsGraph":{"attributes":{"name":"by-function"},"children":[
+				{"vnsAbsNode":{"attributes":{"name":"N1"},"children":[
+					{"vnsRsNodeToMFunc":{"attributes":{"tDn":"uni/infra/mDev-Acme-FW-1.0/mFunc-Firewall"}}}
+				]}}
+			]}},
+			{"vnsAbsGraph":{"attributes":{"name":"unrelated"},"children":[
+				{"vnsAbsNode":{"attributes":{"name":"N1"},"children":[
+					{"vnsRsNodeToLDev":{"attributes":{"tDn":"uni/tn-a/lDevVip-other"}}}
+				]}}
+			]}}
+		]}}
+	]}}`))
+	bkup.Files["packages/Acme.FW.1.0.zip"] = testPackage(t, map[string]string{
+		"DeviceModel.xml": testDeviceModel,
+		"DeviceScript.py": "",
+	})
+	bkup = roundTrip(t, bkup, FormatJSON)
+
+	if !assert.Len(t, bkup.Packages, 1) {
+		t.FailNow()
+	}
+	pkg := bkup.Packages[0]
+	assert.Equal(t, "packages/Acme.FW.1.0.zip", pkg.File)
+	assert.Equal(t, "Acme", pkg.Vendor)
+	assert.Equal(t, "FW", pkg.Model)
+	assert.Equal(t, "1.0", pkg.Version)
+	assert.Equal(t, "GoTo", pkg.FuncMask)
+	assert.Equal(t, "uni/infra/mDev-Acme-FW-1.0", pkg.Dn())
+	assert.Equal(t, []Folder{{
+		Key:    "HostConfig",
+		Label:  "Host Configuration",
+		Params: []Param{{Key: "HostName", Label: "Host Name", Type: "str", Mandatory: true}},
+	}}, pkg.DeviceConfig)
+	if assert.Len(t, pkg.Functions, 1) {
+		assert.Equal(t, "Firewall", pkg.Functions[0].Name)
+		assert.Equal(t, "isDistance", pkg.Functions[0].Folders[0].Folders[0].Params[0].Validation)
+		assert.Equal(t, "n", pkg.Functions[0].Folders[0].Cardinality)
+	}
+
+	assert.Equal(t, PackageDependents{
+		Devices: []string{"uni/tn-a/lDevVip-fw"},
+		Graphs:  []string{"uni/tn-a/AbsGraph-by-device", "uni/tn-a/AbsGraph-by-function"},
+	}, bkup.Dependents(pkg))
+
+	// Corrupt packages are skipped with a warning, and the configuration is still loaded
+	bkup.Files["packages/Corrupt.zip"] = []byte("not a zip")
+	bkup = roundTrip(t, bkup, FormatJSON)
+	assert.Len(t, bkup.Packages, 1)
+	assert.Equal(t, []string{"packages/Corrupt.zip: zip: not a valid zip file"}, bkup.Warnings)
+	res, _ := bkup.GetDn("uni/tn-a/lDevVip-fw")
+	assert.True(t, res.Exists())
+
+	// Invalid packages
+	_, err := parsePackage("invalid.zip", []byte("not a zip"))
+	assert.Error(t, err)
+	_, err = parsePackage("empty.zip", testPackage(t, map[string]string{"README": ""}))
+	assert.Error(t, err)
+	_, err = parsePackage("invalid.zip", testPackage(t, map[string]string{"DeviceModel.xml": "<polUni>"}))
+	assert.Error(t, err)
+}
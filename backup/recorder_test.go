@@ -0,0 +1,33 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecorder tests a dry run against a backup.
+func TestRecorder(t *testing.T) {
+	rec := goaci.NewRecorder(newTestEditClient())
+
+	// Set the BD descriptions in a change script
+	script := func(client goaci.ReadWriter) error {
+		bds, err := client.GetClass("fvBD")
+		if err != nil {
+			return err
+		}
+		for _, bd := range bds.Array() {
+			dn := bd.Get("fvBD.attributes.dn").Str
+			body := goaci.Body{}.Set("fvBD.attributes.descr", "managed")
+			if _, err := client.Post("/api/mo/"+dn, body.Str); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	assert.NoError(t, script(rec))
+	if assert.Len(t, rec.Changes.Changes, 1) {
+		assert.Equal(t, "uni/tn-a/BD-bd", rec.Changes.Changes[0].Dn)
+	}
+}
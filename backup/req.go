@@ -1,6 +1,7 @@
 package backup
 
 import (
+	"github.com/brightpuddle/goaci"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -58,4 +59,7 @@ func (body Body) Res() Res {
 }
 
 // Req is a backup.Req request object.
-type Req struct{}
+// This is the same type as goaci.Req, so request modifiers such as goaci.Query
+// can be passed to the backup client, e.g.
+//  client.GetClass("fvBD", goaci.Query("query-target-filter", `eq(fvBD.name,"bd-name")`))
+type Req = goaci.Req
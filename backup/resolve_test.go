@@ -0,0 +1,63 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// TestResolver tests relation resolution against a backup.
+func TestResolver(t *testing.T) {
+	bkup := newClient()
+	bkup.addToDB(gjson.Parse(`{"polUni":{"attributes":{"dn":"uni"},"children":[
+		{"fvTenant":{"attributes":{"name":"common"},"children":[
+			{"fvCtx":{"attributes":{"name":"vrf"}}},
+			{"vzFilter":{"attributes":{"name":"http"}}}
+		]}},
+		{"fvTenant":{"attributes":{"name":"a"},"children":[
+			{"fvBD":{"attributes":{"name":"bd"},"children":[
+				{"fvRsCtx":{"attributes":{"tnFvCtxName":"vrf"}}}
+			]}},
+			{"vzFilter":{"attributes":{"name":"http"}}},
+			{"vzBrCP":{"attributes":{"name":"web"},"children":[
+				{"vzSubj":{"attributes":{"name":"s"},"children":[
+					{"vzRsSubjFiltAtt":{"attributes":{"tnVzFilterName":"http"}}},
+					{"vzRsSubjFiltAtt":{"attributes":{"tnVzFilterName":"missing"}}}
+				]}}
+			]}},
+			{"fvAp":{"attributes":{"name":"app"},"children":[
+				{"fvAEPg":{"attributes":{"name":"web"},"children":[
+					{"fvRsBd":{"attributes":{"tnFvBDName":"bd"}}}
+				]}}
+			]}}
+		]}}
+	]}}`))
+	resolver := goaci.NewResolver(bkup)
+
+	// EPG to BD to VRF
+	bd, err := resolver.Follow("uni/tn-a/ap-app/epg-web", "fvRsBd")
+	assert.NoError(t, err)
+	assert.Equal(t, "uni/tn-a/BD-bd", bd.Target)
+	vrf, err := resolver.Follow(bd.Target, "fvRsCtx")
+	assert.NoError(t, err)
+	assert.Equal(t, "uni/tn-common/ctx-vrf", vrf.Target)
+
+	// Shadowed and unresolved filters
+	results, err := resolver.ResolveAll("vzRsSubjFiltAtt", "fvRsProv")
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, []string{"uni/tn-common/flt-http"}, results[0].Shadowed)
+		assert.False(t, results[1].Resolved)
+	}
+
+	// All relations
+	results, _ = resolver.ResolveAll()
+	assert.Len(t, results, 4)
+
+	// Not found errors
+	_, err = bkup.GetDn("uni/tn-none")
+	assert.True(t, goaci.IsNotFound(err))
+	assert.False(t, goaci.IsNotFound(nil))
+}
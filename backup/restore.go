@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/brightpuddle/goaci/meta"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ReadOnlyAttributes are the operational attributes of all classes, removed from restore payloads
+// since the APIC rejects or ignores them on POST. The read-only properties of each class are
+// removed according to the metadata models.
+var ReadOnlyAttributes = []string{
+	"childAction",
+	"extMngdBy",
+	"lcOwn",
+	"modTs",
+	"monPolDn",
+	"status",
+	"uid",
+}
+
+// RestoreBody builds a POST body to restore an object and its subtree from the backup.
+// Operational attributes, i.e. ReadOnlyAttributes, are removed, as well as the read-only
+// properties of each class in the registered metadata models, e.g. the pcTag of an fvBD.
+// Configurable properties, e.g. the scope of an fvSubnet, are kept.
+// With redaction enabled secure properties are removed as well.
+// This needs the metadata models, see meta.LoadFile; meta.ErrNoModels is returned otherwise.
+// The result is suitable for restoring e.g. a single tenant, BD or EPG:
+//  body, _ := bkup.RestoreBody("uni/tn-mytenant")
+//  client.Post("/api/mo/uni", body.Str)
+func (client Client) RestoreBody(dn string) (Body, error) {
+	if err := meta.Check(); err != nil {
+		return Body{}, err
+	}
+	obj, ok := client.DNs[dn]
+	if !ok {
+		return Body{}, fmt.Errorf("%s not found", dn)
+	}
+	readOnly := make(map[string]bool)
+	for _, attr := range ReadOnlyAttributes {
+		readOnly[attr] = true
+	}
+	prepare := removeReadOnly
+	if client.redact {
+		prepare = func(obj Res) (Res, error) {
+			obj, err := removeReadOnly(obj)
+			if err != nil {
+				return obj, err
+			}
+			return removeSecure(obj)
+		}
+	}
+	return client.tree(*obj, readOnly, true, prepare)
+}
+
+// removeReadOnly removes the read-only properties of an object according to the metadata models.
+// The DN and naming properties are kept.
+func removeReadOnly(obj Res) (Res, error) {
+	class := className(obj)
+	c, ok := meta.Lookup(class)
+	if !ok {
+		return obj, nil
+	}
+	keep := map[string]bool{"dn": true, "status": true}
+	for _, name := range c.Naming {
+		keep[name] = true
+	}
+	raw := obj.Raw
+	for _, name := range c.ReadOnly() {
+		if keep[name] {
+			continue
+		}
+		var err error
+		raw, err = sjson.Delete(raw, class+".attributes."+name)
+		if err != nil {
+			return obj, err
+		}
+	}
+	return gjson.Parse(raw), nil
+}
@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/brightpuddle/goaci/meta"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// TestClientRestoreBody tests the Client::RestoreBody method.
+func TestClientRestoreBody(t *testing.T) {
+	bkup := newClient()
+	_, err := bkup.RestoreBody("uni")
+	assert.Equal(t, meta.ErrNoModels, err)
+	defer registerTestMeta(t)()
+
+	bkup.addToDB(gjson.Parse(`{"polUni":{"attributes":{"dn":"uni"},"children":[
+		{"fvTenant":{"attributes":{"name":"a","modTs":"now","uid":"15374"},"children":[
+			{"fvBD":{"attributes":{"name":"bd","seg":"16000001","pcTag":"49153"},"children":[
+				{"fvRsCtx":{"attributes":{"tnFvCtxName":"vrf","state":"formed","tCl":"fvCtx"}}}
+			]}},
+			{"fvCtx":{"attributes":{"name":"vrf"}}}
+		]}}
+	]}}`))
+
+	body, err := bkup.RestoreBody("uni/tn-a")
+	assert.NoError(t, err)
+	res := body.Res()
+	if !assert.Equal(t, "uni/tn-a", res.Get("fvTenant.attributes.dn").Str) {
+		fmt.Println(res.Get("@pretty"))
+	}
+	assert.False(t, res.Get("fvTenant.attributes.modTs").Exists())
+	assert.False(t, res.Get("fvTenant.attributes.uid").Exists())
+	assert.Len(t, res.Get("fvTenant.children").Array(), 2)
+
+	bd := res.Get(`fvTenant.children.#(fvBD).fvBD`)
+	assert.Equal(t, `{"name":"bd"}`, bd.Get("attributes").Raw)
+	assert.Equal(t, `{"tnFvCtxName":"vrf"}`, bd.Get("children.0.fvRsCtx.attributes").Raw)
+
+	// Missing DN
+	_, err = bkup.RestoreBody("uni/tn-missing")
+	assert.Error(t, err)
+}
+
+// TestClientRestoreBodyMeta tests the removal of read-only properties from the metadata.
+func TestClientRestoreBodyMeta(t *testing.T) {
+	defer registerTestMeta(t)()
+	bkup := newClient()
+	bkup.addToDB(gjson.Parse(`{"polUni":{"attributes":{"dn":"uni"},"children":[
+		{"fvTenant":{"attributes":{"name":"a"},"children":[
+			{"fvBD":{"attributes":{"name":"bd"},"children":[
+				{"fvRsCtx":{"attributes":{"tnFvCtxName":"vrf","tDn":"uni/tn-a/ctx-vrf"}}}
+			]}}
+		]}}
+	]}}`))
+	body, _ := bkup.RestoreBody("uni/tn-a/BD-bd")
+	assert.Equal(t, `{"tnFvCtxName":"vrf"}`, body.Res().Get("fvBD.children.0.fvRsCtx.attributes").Raw)
+}
+
+// TestClientRestoreBodySubnet tests that configurable properties named like operational attributes
+// of other classes are kept, e.g. the scope of a subnet.
+func TestClientRestoreBodySubnet(t *testing.T) {
+	defer registerTestMeta(t)()
+	bkup := newClient()
+	bkup.addToDB(gjson.Parse(`{"polUni":{"attributes":{"dn":"uni"},"children":[
+		{"fvTenant":{"attributes":{"name":"a"},"children":[
+			{"fvBD":{"attributes":{"name":"bd"},"children":[
+				{"fvSubnet":{"attributes":{"ip":"10.0.0.1/24","scope":"public,shared","modTs":"now"}}}
+			]}}
+		]}}
+	]}}`))
+	body, err := bkup.RestoreBody("uni/tn-a/BD-bd/subnet-[10.0.0.1/24]")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ip":"10.0.0.1/24","scope":"public,shared","dn":"uni/tn-a/BD-bd/subnet-[10.0.0.1/24]"}`,
+		body.Res().Get("fvSubnet.attributes").Raw)
+}
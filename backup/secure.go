@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/brightpuddle/goaci/meta"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Redacted is the value shown in place of secure properties when redaction is enabled.
+const Redacted = "******"
+
+// isSecure indicates whether a property of a class is secure, e.g. a password or key,
+// according to the metadata models.
+func isSecure(class, name string) bool {
+	c, ok := meta.Lookup(class)
+	return ok && c.Properties[name].Secure
+}
+
+// EncryptSecure sets a passphrase to encrypt the secure properties of backups written by
+// Client.Write, and to decrypt them when loading a backup written this way, e.g.
+//  client, _ := backup.NewClient("config.tar.gz", backup.EncryptSecure("my-passphrase"))
+// Encrypted values are base64 encoded AES-256-GCM with the nonce prepended, keyed with the
+// SHA-256 hash of the passphrase. A wrong passphrase fails the authentication of the values.
+// This is goaci's own format: APIC exports leave secure properties out, or encrypt them with
+// the AES export passphrase in an undocumented format, which isn't supported.
+// Secure properties are looked up by class in the metadata models, see meta.LoadFile;
+// NewClient returns meta.ErrNoModels without them.
+func EncryptSecure(passphrase string) func(*Client) {
+	return func(client *Client) {
+		key := sha256.Sum256([]byte(passphrase))
+		client.key = key[:]
+	}
+}
+
+// Redact masks secure properties in query results, diffs, restore bodies and written backups, e.g.
+//  client, _ := backup.NewClient("config.tar.gz", backup.EncryptSecure("my-passphrase"), backup.Redact)
+// Use Client.Secret to read a decrypted value explicitly.
+// Like EncryptSecure, this needs the metadata models.
+func Redact(client *Client) {
+	client.redact = true
+}
+
+// nonceReader is the source of encryption nonces, fixed in tests for known answers.
+var nonceReader = rand.Reader
+
+// newGCM creates the authenticated cipher for secure properties.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decrypt decrypts a secure property value.
+func decrypt(key []byte, value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize()+gcm.Overhead() {
+		return "", errors.New("invalid ciphertext length")
+	}
+	nonce, data := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		return "", errors.New("authentication failed")
+	}
+	return string(plain), nil
+}
+
+// encrypt encrypts a secure property value.
+func encrypt(key []byte, value string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(nonceReader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), nil)), nil
+}
+
+// transformSecure applies a function to the non-empty secure properties of an object.
+func transformSecure(obj Res, fn func(string) (string, error)) (Res, error) {
+	class := className(obj)
+	body := Body{Str: obj.Raw}
+	var err error
+	obj.Get(class + ".attributes").ForEach(func(key, value Res) bool {
+		if !isSecure(class, key.Str) || value.String() == "" {
+			return true
+		}
+		var result string
+		result, err = fn(value.String())
+		if err != nil {
+			err = fmt.Errorf("%s %s: %v", dnOf(obj), key.Str, err)
+			return false
+		}
+		body = body.Set(class+".attributes."+key.Str, result)
+		return true
+	})
+	return body.Res(), err
+}
+
+// removeSecure removes the secure properties of an object.
+func removeSecure(obj Res) (Res, error) {
+	class := className(obj)
+	c, ok := meta.Lookup(class)
+	if !ok {
+		return obj, nil
+	}
+	raw := obj.Raw
+	for _, name := range c.Secure() {
+		var err error
+		raw, err = sjson.Delete(raw, class+".attributes."+name)
+		if err != nil {
+			return obj, err
+		}
+	}
+	return gjson.Parse(raw), nil
+}
+
+// decryptAll decrypts the secure properties of all objects.
+func (client Client) decryptAll() error {
+	for _, obj := range client.DNs {
+		decrypted, err := transformSecure(*obj, func(value string) (string, error) {
+			result, err := decrypt(client.key, value)
+			if err != nil {
+				return "", fmt.Errorf("cannot decrypt (not written by goaci with this passphrase?): %v", err)
+			}
+			return result, nil
+		})
+		if err != nil {
+			return err
+		}
+		*obj = decrypted
+	}
+	return nil
+}
+
+// redacted returns an object with secure properties masked if redaction is enabled.
+func (client Client) redacted(obj Res) Res {
+	if !client.redact {
+		return obj
+	}
+	masked, _ := transformSecure(obj, func(string) (string, error) {
+		return Redacted, nil
+	})
+	return masked
+}
+
+// Secret returns the value of a secure property.
+// This value is not redacted, so take care not to expose it.
+func (client Client) Secret(dn, attr string) (string, error) {
+	obj, ok := client.DNs[dn]
+	if !ok {
+		return "", fmt.Errorf("%s not found", dn)
+	}
+	value := obj.Get(className(*obj) + ".attributes." + attr)
+	if !value.Exists() {
+		return "", fmt.Errorf("%s has no attribute %s", dn, attr)
+	}
+	return value.String(), nil
+}
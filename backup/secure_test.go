@@ -0,0 +1,217 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brightpuddle/goaci/meta"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// newTestSecureClient creates an in-memory backup with a decrypted secure property.
+func newTestSecureClient(passphrase string) Client {
+	bkup := newClient()
+	EncryptSecure(passphrase)(&bkup)
+	bkup.addToDB(gjson.Parse(`{"polUni":{"attributes":{"dn":"uni"},"children":[
+		{"aaaUserEp":{"attributes":{"dn":"uni/userext"},"children":[
+			{"aaaUser":{"attributes":{"name":"admin","pwd":"secret-password","descr":""}}}
+		]}}
+	]}}`))
+	return bkup
+}
+
+// TestEncryptDecrypt tests the encrypt and decrypt functions.
+func TestEncryptDecrypt(t *testing.T) {
+	key := sha256.Sum256([]byte("passphrase"))
+	for _, value := range []string{"a", "exactly 16 bytes", "a longer value spanning blocks"} {
+		encrypted, err := encrypt(key[:], value)
+		assert.NoError(t, err)
+		assert.NotEqual(t, value, encrypted)
+		decrypted, err := decrypt(key[:], encrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, value, decrypted)
+	}
+
+	// Known answer with a fixed nonce
+	nonceReader = bytes.NewReader([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+	const known = "AAECAwQFBgcICQoLl4qnh+/oNhPxH48/e+QXQbkUXAXtXcnT9MJ+fTfj4w=="
+	encrypted, err := encrypt(key[:], "secret-password")
+	nonceReader = rand.Reader
+	assert.NoError(t, err)
+	assert.Equal(t, known, encrypted)
+	decrypted, err := decrypt(key[:], known)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret-password", decrypted)
+
+	// Wrong key, for many values so that a check by chance would show
+	wrongKey := sha256.Sum256([]byte("wrong"))
+	for i := 0; i < 1000; i++ {
+		encrypted, _ := encrypt(key[:], "value")
+		_, err := decrypt(wrongKey[:], encrypted)
+		if !assert.EqualError(t, err, "authentication failed") {
+			break
+		}
+	}
+
+	// Modified ciphertext
+	data, _ := base64.StdEncoding.DecodeString(known)
+	data[len(data)-1] ^= 1
+	_, err = decrypt(key[:], base64.StdEncoding.EncodeToString(data))
+	assert.EqualError(t, err, "authentication failed")
+
+	// Invalid ciphertext
+	_, err = decrypt(key[:], "not base64!")
+	assert.Error(t, err)
+	_, err = decrypt(key[:], "c2hvcnQ=")
+	assert.EqualError(t, err, "invalid ciphertext length")
+}
+
+// TestEncryptSecure tests writing and loading an encrypted backup with the EncryptSecure modifier.
+func TestEncryptSecure(t *testing.T) {
+	bkup := newTestSecureClient("passphrase")
+
+	// Secure properties are looked up in the metadata
+	_, err := NewClient("./testdata/json_config.tar.gz", EncryptSecure("passphrase"))
+	assert.Equal(t, meta.ErrNoModels, err)
+	_, err = NewClient("./testdata/json_config.tar.gz", Redact)
+	assert.Equal(t, meta.ErrNoModels, err)
+	defer registerTestMeta(t)()
+
+	// Written backups are encrypted
+	plain := roundTrip(t, bkup, FormatJSON)
+	res, _ := plain.GetDn("uni/userext/user-admin")
+	encrypted := res.Get("aaaUser.attributes.pwd").Str
+	assert.NotEqual(t, "secret-password", encrypted)
+	assert.NotEmpty(t, encrypted)
+
+	// Decrypted on load
+	dst, cleanup := writeTemp(t, bkup, FormatJSON)
+	defer cleanup()
+	decrypted, err := NewClient(dst, EncryptSecure("passphrase"))
+	assert.NoError(t, err)
+	pwd, err := decrypted.Secret("uni/userext/user-admin", "pwd")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret-password", pwd)
+
+	// Wrong passphrase
+	_, err = NewClient(dst, EncryptSecure("wrong"))
+	assert.EqualError(t, err, "uni/userext/user-admin pwd: cannot decrypt (not written by goaci with this passphrase?): authentication failed")
+}
+
+// tarExport archives an extracted APIC export directory as a tar.gz file.
+func tarExport(t *testing.T, dir string) (string, func()) {
+	tmp, err := ioutil.TempDir("", "goaci")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	dst := filepath.Join(tmp, "export.tar.gz")
+	f, err := os.Create(dst)
+	if !assert.NoError(t, err) {
+		os.RemoveAll(tmp)
+		t.FailNow()
+	}
+	gzw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gzw)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, _ := filepath.Rel(dir, path)
+		header := &tar.Header{Name: filepath.ToSlash(name), Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	})
+	for _, closer := range []io.Closer{tw, gzw, f} {
+		if err == nil {
+			err = closer.Close()
+		}
+	}
+	if !assert.NoError(t, err) {
+		os.RemoveAll(tmp)
+		t.FailNow()
+	}
+	return dst, func() { os.RemoveAll(tmp) }
+}
+
+// TestEncryptSecureExport tests the secure property modifiers with an unencrypted APIC export.
+func TestEncryptSecureExport(t *testing.T) {
+	defer registerTestMeta(t)()
+	src, cleanup := tarExport(t, "./tmp")
+	defer cleanup()
+	bkup, err := NewClient(src, EncryptSecure("passphrase"), Redact)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// Annotation keys aren't secure
+	res, err := bkup.GetDn("uni/tn-infra/fabricExtConnP-2/annotationKey-[mscSiteName]")
+	assert.NoError(t, err)
+	assert.Equal(t, "mscSiteName", res.Get("tagAnnotation.attributes.key").Str)
+	assert.Equal(t, "GTN-B12-Fabric", res.Get("tagAnnotation.attributes.value").Str)
+
+	// Secure properties are left empty by the APIC
+	res, _ = bkup.GetDn("uni/tn-common/pimifpol-default")
+	assert.Equal(t, "", res.Get("pimIfPol.attributes.authKey").Str)
+	assert.Equal(t, "none", res.Get("pimIfPol.attributes.authT").Str)
+
+	// Written with secure properties left out
+	written := roundTrip(t, bkup, FormatJSON)
+	res, _ = written.GetDn("uni/tn-common/pimifpol-default")
+	assert.False(t, res.Get("pimIfPol.attributes.authKey").Exists())
+	res, _ = written.GetDn("uni/tn-infra/fabricExtConnP-2/annotationKey-[mscSiteName]")
+	assert.Equal(t, "mscSiteName", res.Get("tagAnnotation.attributes.key").Str)
+}
+
+// TestRedact tests the Redact modifier.
+func TestRedact(t *testing.T) {
+	defer registerTestMeta(t)()
+	bkup := newTestSecureClient("passphrase")
+	Redact(&bkup)
+
+	// Query results
+	res, _ := bkup.GetDn("uni/userext/user-admin")
+	assert.Equal(t, Redacted, res.Get("aaaUser.attributes.pwd").Str)
+	assert.Equal(t, "", res.Get("aaaUser.attributes.descr").Str)
+	res, _ = bkup.GetClass("aaaUser")
+	assert.Equal(t, Redacted, res.Get("0.aaaUser.attributes.pwd").Str)
+
+	// Secret values are still available explicitly
+	pwd, _ := bkup.Secret("uni/userext/user-admin", "pwd")
+	assert.Equal(t, "secret-password", pwd)
+	_, err := bkup.Secret("uni/userext/user-admin", "missing")
+	assert.Error(t, err)
+	_, err = bkup.Secret("uni/missing", "pwd")
+	assert.Error(t, err)
+
+	// Restore bodies and written backups leave out secure properties
+	body, _ := bkup.RestoreBody("uni/userext/user-admin")
+	assert.False(t, body.Res().Get("aaaUser.attributes.pwd").Exists())
+	written := roundTrip(t, bkup, FormatXML)
+	res, _ = written.GetDn("uni/userext/user-admin")
+	assert.False(t, res.Get("aaaUser.attributes.pwd").Exists())
+
+	// Diffs
+	other := newTestSecureClient("passphrase")
+	other.SetAttributes("uni/userext/user-admin", map[string]string{"pwd": "changed"})
+	diff := Diff(bkup, other)
+	if assert.Len(t, diff.Modified, 1) {
+		assert.Equal(t, []AttributeChange{{Name: "pwd", Old: Redacted, New: Redacted}}, diff.Modified[0].Changes)
+	}
+}
@@ -0,0 +1,142 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Backup file formats for Client.Write.
+const (
+	FormatJSON = "json"
+	FormatXML  = "xml"
+)
+
+// configNames returns the archive paths for the configuration files.
+// The first file keeps the name of the original configuration file.
+func (client Client) configNames(format string, n int) []string {
+	name := client.config
+	if name == "" {
+		name = "config_1." + format
+	}
+	base := strings.TrimSuffix(name, path.Ext(name))
+	names := []string{base + "." + format}
+	base = strings.TrimSuffix(base, "_1")
+	for i := 2; i <= n; i++ {
+		names = append(names, fmt.Sprintf("%s_%d.%s", base, i, format))
+	}
+	return names
+}
+
+// roots returns the top level objects, e.g. polUni.
+func (client Client) roots() (roots []*Res) {
+	seen := make(map[string]bool)
+	for _, obj := range client.Children[""] {
+		dn := dnOf(*obj)
+		if !seen[dn] {
+			seen[dn] = true
+			roots = append(roots, client.DNs[dn])
+		}
+	}
+	return roots
+}
+
+// Write writes the backup in the APIC export layout, i.e. a tar.gz archive containing the
+// configuration as JSON or XML, along with the other files from the original backup.
+// Secure properties decrypted with EncryptSecure are encrypted again, and secure properties
+// are left out entirely with redaction enabled.
+// The result can be imported by the APIC:
+//  bkup.SetAttributes("uni/tn-old", map[string]string{"name": "new"})
+//  bkup.Write(f, backup.FormatXML)
+func (client Client) Write(w io.Writer, format string) error {
+	if format != FormatJSON && format != FormatXML {
+		return fmt.Errorf("invalid format %s", format)
+	}
+
+	// Secure properties
+	var prepare func(Res) (Res, error)
+	switch {
+	case client.redact:
+		prepare = removeSecure
+	case client.key != nil:
+		prepare = func(obj Res) (Res, error) {
+			return transformSecure(obj, func(value string) (string, error) {
+				return encrypt(client.key, value)
+			})
+		}
+	}
+
+	// Configuration files
+	roots := client.roots()
+	names := client.configNames(format, len(roots))
+	files := make(map[string][]byte)
+	for i, root := range roots {
+		tree, err := client.tree(*root, nil, true, prepare)
+		if err != nil {
+			return err
+		}
+		if format == FormatJSON {
+			files[names[i]] = []byte(tree.Str)
+			continue
+		}
+		var b bytes.Buffer
+		bw := bufio.NewWriter(&b)
+		bw.WriteString("<?xml version=\"1.0\"?>\n")
+		writeXML(bw, tree.Res(), "")
+		bw.Flush()
+		files[names[i]] = b.Bytes()
+	}
+	for name, data := range client.Files {
+		files[name] = data
+	}
+
+	paths := make([]string, 0, len(files))
+	for name := range files {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+	now := time.Now()
+	for _, name := range paths {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(files[name])),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gzw.Close()
+}
+
+// WriteFile writes the backup to a tar.gz file.
+// See Write for details.
+func (client Client) WriteFile(dst, format string) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := client.Write(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
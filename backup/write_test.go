@@ -0,0 +1,70 @@
+package backup
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTemp writes a backup to a temporary file.
+// Call the returned function to remove the file.
+func writeTemp(t *testing.T, bkup Client, format string) (string, func()) {
+	dir, err := ioutil.TempDir("", "goaci")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	dst := filepath.Join(dir, "backup.tar.gz")
+	if !assert.NoError(t, bkup.WriteFile(dst, format)) {
+		os.RemoveAll(dir)
+		t.FailNow()
+	}
+	return dst, func() { os.RemoveAll(dir) }
+}
+
+// roundTrip writes a backup and loads the result.
+func roundTrip(t *testing.T, bkup Client, format string) Client {
+	dst, cleanup := writeTemp(t, bkup, format)
+	defer cleanup()
+	result, err := NewClient(dst)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return result
+}
+
+// TestClientWrite tests the Client::Write and Client::WriteFile methods.
+func TestClientWrite(t *testing.T) {
+	bkup, _ := testClient()
+
+	// JSON round-trip
+	result := roundTrip(t, bkup, FormatJSON)
+	assert.True(t, Diff(bkup, result).Empty(), Diff(bkup, result).String())
+	assert.Equal(t, "config.json", result.config)
+
+	// XML round-trip
+	result = roundTrip(t, bkup, FormatXML)
+	assert.True(t, Diff(bkup, result).Empty(), Diff(bkup, result).String())
+	assert.Equal(t, "config.xml", result.config)
+
+	// Modified backup with metadata files
+	edited := newTestEditClient()
+	edited.Files["idconfig/config_1_idfile.xml"] = []byte("<topRoot/>")
+	assert.NoError(t, edited.SetAttributes("uni/tn-a", map[string]string{"name": "c", "descr": `<"quoted">`}))
+	for _, format := range []string{FormatJSON, FormatXML} {
+		result = roundTrip(t, edited, format)
+		assert.True(t, Diff(edited, result).Empty(), Diff(edited, result).String())
+		assert.Equal(t, edited.Files, result.Files)
+		res, _ := result.GetDn("uni/tn-c/BD-bd/subnet-[10.0.0.1/24]")
+		assert.True(t, res.Exists())
+	}
+
+	// Invalid format
+	assert.Error(t, bkup.Write(&bytes.Buffer{}, "yaml"))
+
+	// Invalid destination
+	assert.Error(t, bkup.WriteFile("/non/existent/backup.tar.gz", FormatJSON))
+}
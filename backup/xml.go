@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// xmlNode is an MO parsed from an XML backup file.
+type xmlNode struct {
+	class    string
+	attrs    []xml.Attr
+	children []*xmlNode
+}
+
+// writeJSON writes the node in the JSON backup format, i.e.
+//  {"moClass": {"attributes": {...}, "children": [...]}}
+func (node *xmlNode) writeJSON(b *strings.Builder) {
+	quote := func(s string) string {
+		data, _ := json.Marshal(s)
+		return string(data)
+	}
+	b.WriteString("{" + quote(node.class) + `:{"attributes":{`)
+	for i, attr := range node.attrs {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(quote(attr.Name.Local) + ":" + quote(attr.Value))
+	}
+	b.WriteString("}")
+	if len(node.children) > 0 {
+		b.WriteString(`,"children":[`)
+		for i, child := range node.children {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			child.writeJSON(b)
+		}
+		b.WriteString("]")
+	}
+	b.WriteString("}}")
+}
+
+// xmlToJSON converts an XML backup file to the JSON backup format.
+func xmlToJSON(r io.Reader) (gjson.Result, error) {
+	decoder := xml.NewDecoder(r)
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return gjson.Result{}, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{class: t.Name.Local, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if root == nil {
+		return gjson.Result{}, errors.New("no objects found")
+	}
+	var b strings.Builder
+	root.writeJSON(&b)
+	return gjson.Parse(b.String()), nil
+}
+
+// escapeXML escapes a string for use as an XML attribute value.
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// writeXML writes an object in the JSON backup format as XML.
+func writeXML(w *bufio.Writer, obj Res, indent string) {
+	class := className(obj)
+	w.WriteString(indent + "<" + class)
+	obj.Get(class + ".attributes").ForEach(func(key, value Res) bool {
+		w.WriteString(" " + key.Str + `="` + escapeXML(value.String()) + `"`)
+		return true
+	})
+	children := obj.Get(class + ".children").Array()
+	if len(children) == 0 {
+		w.WriteString("/>\n")
+		return
+	}
+	w.WriteString(">\n")
+	for _, child := range children {
+		writeXML(w, child, indent+"  ")
+	}
+	w.WriteString(indent + "</" + class + ">\n")
+}
@@ -0,0 +1,30 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestXMLToJSON tests the xmlToJSON function.
+func TestXMLToJSON(t *testing.T) {
+	res, err := xmlToJSON(strings.NewReader(`<?xml version="1.0"?>
+<polUni dn="uni">
+  <fvTenant name="a" descr="&quot;quoted&quot;">
+    <fvCtx name="vrf"/>
+  </fvTenant>
+</polUni>`))
+	assert.NoError(t, err)
+	assert.Equal(t, "uni", res.Get("polUni.attributes.dn").Str)
+	assert.Equal(t, `"quoted"`, res.Get("polUni.children.0.fvTenant.attributes.descr").Str)
+	assert.Equal(t, "vrf", res.Get("polUni.children.0.fvTenant.children.0.fvCtx.attributes.name").Str)
+
+	// Invalid XML
+	_, err = xmlToJSON(strings.NewReader(`<polUni>`))
+	assert.Error(t, err)
+
+	// No objects
+	_, err = xmlToJSON(strings.NewReader(`<?xml version="1.0"?>`))
+	assert.Error(t, err)
+}
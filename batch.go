@@ -0,0 +1,156 @@
+package goaci
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BatchQuery is a class or DN query of a batch. See Client.Batch.
+type BatchQuery struct {
+	// Key is the key of the result. Defaults to the class or DN.
+	Key   string
+	Class string
+	Dn    string
+	Mods  []func(*Req)
+}
+
+// ClassQuery creates a batch query for a class.
+func ClassQuery(class string, mods ...func(*Req)) BatchQuery {
+	return BatchQuery{Class: class, Mods: mods}
+}
+
+// DnQuery creates a batch query for a DN.
+func DnQuery(dn string, mods ...func(*Req)) BatchQuery {
+	return BatchQuery{Dn: dn, Mods: mods}
+}
+
+// As sets the key of the query result, e.g. to query the same class with different filters.
+func (query BatchQuery) As(key string) BatchQuery {
+	query.Key = key
+	return query
+}
+
+// key returns the key of the query result.
+func (query BatchQuery) key() string {
+	switch {
+	case query.Key != "":
+		return query.Key
+	case query.Class != "":
+		return query.Class
+	}
+	return query.Dn
+}
+
+// BatchResult is the result of a batch query, i.e. the result of GetClass or GetDn.
+type BatchResult struct {
+	Res Res
+	Err error
+}
+
+// BatchOptions are the options of a batch.
+type BatchOptions struct {
+	// Concurrency is the maximum number of requests in flight. Defaults to 4.
+	Concurrency int
+	// Rate is the maximum number of requests started per second, or 0 for no limit.
+	Rate float64
+}
+
+// BatchConcurrency modifies the maximum number of requests in flight.
+func BatchConcurrency(n int) func(*BatchOptions) {
+	return func(opts *BatchOptions) {
+		opts.Concurrency = n
+	}
+}
+
+// RateLimit limits the number of requests started per second, e.g. to stay below the APIC
+// request throttle.
+func RateLimit(perSecond float64) func(*BatchOptions) {
+	return func(opts *BatchOptions) {
+		opts.Rate = perSecond
+	}
+}
+
+// Batch runs multiple queries concurrently, e.g. for a dashboard:
+//  results, err := client.Batch([]goaci.BatchQuery{
+//    goaci.ClassQuery("fabricNode"),
+//    goaci.ClassQuery("faultInst", goaci.Query("query-target-filter", `eq(faultInst.severity,"critical")`)).As("critical"),
+//    goaci.DnQuery("topology/pod-1/node-1/sys"),
+//  }, goaci.RateLimit(10))
+//  nodes := results["fabricNode"].Res
+// Results are keyed by query and have individual errors. The returned error is for the batch
+// itself, i.e. duplicate keys or a failed token refresh before the queries are sent.
+// A session restored with CacheSession is verified once before the queries are sent, logging
+// in again if needed, since the concurrent queries don't refresh the token or log in.
+func (client *Client) Batch(queries []BatchQuery, mods ...func(*BatchOptions)) (map[string]BatchResult, error) {
+	opts := BatchOptions{Concurrency: 4}
+	for _, mod := range mods {
+		mod(&opts)
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	seen := make(map[string]bool)
+	for _, query := range queries {
+		if query.Class == "" && query.Dn == "" {
+			return nil, errors.New("batch query without class or DN")
+		}
+		key := query.key()
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate batch query %s", key)
+		}
+		seen[key] = true
+	}
+	// Verify the session and refresh once up front, so that concurrent requests don't change the client
+	if err := client.verifySession(); err != nil {
+		return nil, err
+	}
+
+	// wait waits for the next request slot of the rate limit
+	var mu sync.Mutex
+	next := time.Now()
+	wait := func() {
+		if opts.Rate <= 0 {
+			return
+		}
+		mu.Lock()
+		now := time.Now()
+		if next.Before(now) {
+			next = now
+		}
+		delay := next.Sub(now)
+		next = next.Add(time.Duration(float64(time.Second) / opts.Rate))
+		mu.Unlock()
+		time.Sleep(delay)
+	}
+
+	results := make([]BatchResult, len(queries))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, query BatchQuery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			wait()
+			mods := append(append([]func(*Req){}, query.Mods...), NoRefresh)
+			var res Res
+			var err error
+			if query.Class != "" {
+				res, err = client.GetClass(query.Class, mods...)
+			} else {
+				res, err = client.GetDn(query.Dn, mods...)
+			}
+			results[i] = BatchResult{Res: res, Err: err}
+		}(i, query)
+	}
+	wg.Wait()
+
+	byKey := make(map[string]BatchResult)
+	for i, query := range queries {
+		byKey[query.key()] = results[i]
+	}
+	return byKey, nil
+}
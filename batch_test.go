@@ -0,0 +1,85 @@
+package goaci
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// TestClientBatch tests batch queries.
+func TestClientBatch(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+
+	gock.New(testURL).
+		Get("/api/class/fabricNode.json").
+		Reply(200).
+		BodyString(`{"imdata":[{"fabricNode":{"attributes":{"dn":"topology/pod-1/node-101"}}}]}`)
+	gock.New(testURL).
+		Get("/api/class/faultInst.json").
+		MatchParam("query-target-filter", `eq\(faultInst.severity,"critical"\)`).
+		Reply(200).
+		BodyString(`{"imdata":[{"faultInst":{"attributes":{"code":"F0001"}}}]}`)
+	gock.New(testURL).
+		Get("/api/mo/topology/pod-1/node-1/sys.json").
+		Reply(200).
+		BodyString(`{"imdata":[{"topSystem":{"attributes":{"version":"5.2(1g)"}}}]}`)
+	gock.New(testURL).Get("/api/class/firmwareRunning.json").Reply(500)
+
+	results, err := client.Batch([]BatchQuery{
+		ClassQuery("fabricNode"),
+		ClassQuery("faultInst", Query("query-target-filter", `eq(faultInst.severity,"critical")`)).As("critical"),
+		DnQuery("topology/pod-1/node-1/sys"),
+		ClassQuery("firmwareRunning"),
+	})
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	assert.Len(t, results, 4)
+	assert.Equal(t, "topology/pod-1/node-101", results["fabricNode"].Res.Get("0.fabricNode.attributes.dn").Str)
+	assert.NoError(t, results["fabricNode"].Err)
+	assert.Equal(t, "F0001", results["critical"].Res.Get("0.faultInst.attributes.code").Str)
+	assert.Equal(t, "5.2(1g)", results["topology/pod-1/node-1/sys"].Res.Get("topSystem.attributes.version").Str)
+	assert.EqualError(t, results["firmwareRunning"].Err, "received HTTP status 500")
+
+	// Invalid queries
+	_, err = client.Batch([]BatchQuery{ClassQuery("fvTenant"), ClassQuery("fvTenant")})
+	assert.EqualError(t, err, "duplicate batch query fvTenant")
+	_, err = client.Batch([]BatchQuery{{Key: "empty"}})
+	assert.EqualError(t, err, "batch query without class or DN")
+
+	// The token is refreshed once before the queries
+	client.LastRefresh = time.Now().AddDate(0, 0, -1)
+	gock.New(testURL).Get("/api/aaaRefresh.json").Times(1).Reply(200)
+	gock.New(testURL).Get("/api/class/fvTenant.json").Reply(200)
+	gock.New(testURL).Get("/api/class/fvBD.json").Reply(200)
+	_, err = client.Batch([]BatchQuery{ClassQuery("fvTenant"), ClassQuery("fvBD")})
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+
+	client.LastRefresh = time.Now().AddDate(0, 0, -1)
+	gock.New(testURL).Get("/api/aaaRefresh.json").ReplyError(errors.New("fail"))
+	_, err = client.Batch([]BatchQuery{ClassQuery("fvTenant")})
+	assert.Error(t, err)
+}
+
+// TestClientBatchRateLimit tests the concurrency and rate limits of batch queries.
+func TestClientBatchRateLimit(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+
+	var queries []BatchQuery
+	for _, class := range []string{"fvTenant", "fvBD", "fvAEPg", "fvCtx"} {
+		gock.New(testURL).Get("/api/class/" + class + ".json").Reply(200)
+		queries = append(queries, ClassQuery(class))
+	}
+	start := time.Now()
+	results, err := client.Batch(queries, BatchConcurrency(2), RateLimit(20))
+	assert.NoError(t, err)
+	assert.Len(t, results, 4)
+	assert.True(t, gock.IsDone())
+	// 4 requests at 20 per second start over at least 150ms
+	assert.True(t, time.Since(start) >= 150*time.Millisecond)
+}
@@ -0,0 +1,88 @@
+package goaci
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// CertAuth authenticates with a user certificate instead of a password, i.e. APIC signature-based
+// authentication. Each request is signed with the private key of the certificate, so no login or
+// token refresh is needed, e.g.
+//  key, _ := goaci.ReadPrivateKey("admin.key")
+//  client, _ := goaci.NewClient("apic", "admin", "", goaci.CertAuth("admin-cert", key))
+// The certificate name is the name of the aaaUserCert object of the user.
+// Note that multipart uploads can't be signed.
+func CertAuth(certName string, key *rsa.PrivateKey) func(*Client) {
+	return func(client *Client) {
+		client.CertName = certName
+		client.Key = key
+	}
+}
+
+// ReadPrivateKey reads an RSA private key from a PEM file, in PKCS #1 or PKCS #8 format.
+func ReadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an RSA key", path)
+	}
+	return rsaKey, nil
+}
+
+// sign signs a request for certificate authentication.
+// The signature covers the method, path with query and body of the request.
+func (client *Client) sign(req Req) error {
+	payload := req.HttpReq.Method + req.HttpReq.URL.RequestURI()
+	if req.HttpReq.GetBody != nil {
+		body, err := req.HttpReq.GetBody()
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+		payload += string(data)
+	} else if req.HttpReq.Body != nil && req.HttpReq.Body != http.NoBody {
+		return errors.New("cannot sign a streamed request body")
+	}
+	hash := sha256.Sum256([]byte(payload))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, client.Key, crypto.SHA256, hash[:])
+	if err != nil {
+		return err
+	}
+	for name, value := range map[string]string{
+		"APIC-Request-Signature":       base64.StdEncoding.EncodeToString(signature),
+		"APIC-Certificate-Algorithm":   "v1.0",
+		"APIC-Certificate-Fingerprint": "fingerprint",
+		"APIC-Certificate-DN": fmt.Sprintf("uni/userext/user-%s/usercert-%s",
+			client.Usr, client.CertName),
+	} {
+		req.HttpReq.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package goaci
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// TestReadPrivateKey tests reading PKCS #1 and PKCS #8 keys.
+func TestReadPrivateKey(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "goaci")
+	defer os.RemoveAll(dir)
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+	pkcs1 := filepath.Join(dir, "pkcs1.key")
+	ioutil.WriteFile(pkcs1, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600)
+	read, err := ReadPrivateKey(pkcs1)
+	assert.NoError(t, err)
+	assert.Equal(t, key.N, read.N)
+
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	pkcs8 := filepath.Join(dir, "pkcs8.key")
+	ioutil.WriteFile(pkcs8, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	read, err = ReadPrivateKey(pkcs8)
+	assert.NoError(t, err)
+	assert.Equal(t, key.N, read.N)
+
+	invalid := filepath.Join(dir, "invalid.key")
+	ioutil.WriteFile(invalid, []byte("not a key"), 0600)
+	_, err = ReadPrivateKey(invalid)
+	assert.Error(t, err)
+	_, err = ReadPrivateKey(filepath.Join(dir, "missing.key"))
+	assert.Error(t, err)
+}
+
+// TestCertAuth tests signing requests with a user certificate.
+func TestCertAuth(t *testing.T) {
+	defer gock.Off()
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	client, _ := NewClient(testHost, "admin", "", CertAuth("admin-cert", key))
+	gock.InterceptClient(client.HttpClient)
+
+	// verify checks the signature cookies of a request.
+	verify := func(req *http.Request, _ *gock.Request) (bool, error) {
+		dn, err := req.Cookie("APIC-Certificate-DN")
+		if err != nil || dn.Value != "uni/userext/user-admin/usercert-admin-cert" {
+			return false, err
+		}
+		cookie, err := req.Cookie("APIC-Request-Signature")
+		if err != nil {
+			return false, err
+		}
+		signature, _ := base64.StdEncoding.DecodeString(cookie.Value)
+		payload := req.Method + req.URL.RequestURI()
+		if req.GetBody != nil {
+			body, _ := req.GetBody()
+			data, _ := ioutil.ReadAll(body)
+			payload += string(data)
+		}
+		hash := sha256.Sum256([]byte(payload))
+		return rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], signature) == nil, nil
+	}
+
+	// No login or refresh
+	assert.NoError(t, client.Login())
+	assert.NoError(t, client.Refresh())
+
+	gock.New(testURL).Get("/api/class/fvTenant.json").AddMatcher(verify).Reply(200)
+	_, err := client.GetClass("fvTenant", Query("rsp-subtree", "full"))
+	assert.NoError(t, err)
+
+	gock.New(testURL).Post("/api/mo/uni/tn-a.json").AddMatcher(verify).Reply(200)
+	_, err = client.Post("/api/mo/uni/tn-a", `{"fvTenant":{"attributes":{"name":"a"}}}`)
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+
+	// Streamed bodies can't be signed
+	_, err = client.Upload("/upload", "file", strings.NewReader("data"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package goaci
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Change is a recorded write request, i.e. a POST or DELETE.
+type Change struct {
+	Method string `json:"method"`
+	// Path is the request path without the .json suffix, e.g. /api/mo/uni/tn-a.
+	Path string `json:"path"`
+	// Dn is the DN for /api/mo requests.
+	Dn string `json:"dn,omitempty"`
+	// Body is the JSON body of a POST.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// ChangeSet is an ordered list of write requests, e.g. recorded in dry-run mode.
+type ChangeSet struct {
+	Changes []Change `json:"changes"`
+}
+
+// pathDn returns the DN of an /api/mo/ path, or an empty string for other paths.
+func pathDn(path string) string {
+	if !strings.HasPrefix(path, "/api/mo/") {
+		return ""
+	}
+	dn := strings.TrimPrefix(path, "/api/mo/")
+	if i := strings.Index(dn, "?"); i >= 0 {
+		dn = dn[:i]
+	}
+	return dn
+}
+
+// add records a request, keeping query parameters set by request modifiers.
+// These are merged into the query of the path, e.g. /api/mo/uni?rsp-subtree=full.
+func (cs *ChangeSet) add(method, path, data string, mods ...func(*Req)) {
+	u, err := url.Parse(path)
+	if err != nil {
+		u = &url.URL{Path: path}
+	}
+	req := Req{HttpReq: &http.Request{Method: method, URL: u, Header: make(http.Header)}}
+	for _, mod := range mods {
+		mod(&req)
+	}
+	path = strings.SplitN(path, "?", 2)[0]
+	if query := req.HttpReq.URL.RawQuery; query != "" {
+		path += "?" + query
+	}
+	change := Change{Method: method, Path: path, Dn: pathDn(path)}
+	if data != "" {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, []byte(data), "", "  "); err == nil {
+			change.Body = pretty.Bytes()
+		} else {
+			// Keep invalid bodies for review, as a JSON string
+			change.Body, _ = json.Marshal(data)
+		}
+	}
+	cs.Changes = append(cs.Changes, change)
+}
+
+// JSON returns the change set as indented JSON, e.g. for peer review.
+func (cs ChangeSet) JSON() string {
+	data, _ := json.MarshalIndent(cs, "", "  ")
+	return string(data)
+}
+
+// String returns the change set as human-readable text, e.g.
+//  POST /api/mo/uni/tn-a
+//  {
+//    "fvTenant": {
+//      "attributes": {
+//        "name": "a"
+//      }
+//    }
+//  }
+//  DELETE /api/mo/uni/tn-b
+func (cs ChangeSet) String() string {
+	var text strings.Builder
+	for _, change := range cs.Changes {
+		fmt.Fprintf(&text, "%s %s\n", change.Method, change.Path)
+		if len(change.Body) > 0 {
+			text.Write(change.Body)
+			text.WriteString("\n")
+		}
+	}
+	return text.String()
+}
+
+// WriteFile writes the change set to a JSON file, e.g. for peer review and later replay.
+func (cs ChangeSet) WriteFile(path string) error {
+	return ioutil.WriteFile(path, []byte(cs.JSON()+"\n"), 0644)
+}
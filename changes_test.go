@@ -0,0 +1,92 @@
+package goaci
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// TestDryRun tests recording writes in dry-run mode.
+func TestDryRun(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	DryRun(&client)
+
+	// Login and reads are sent
+	gock.New(testURL).Post("/api/aaaLogin.json").Reply(200)
+	assert.NoError(t, client.Login())
+	gock.New(testURL).Get("/api/mo/uni/tn-a.json").Reply(200)
+	_, err := client.GetDn("uni/tn-a")
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+
+	// Writes are recorded
+	res, err := client.Post("/api/mo/uni/tn-a", `{"fvTenant":{"attributes":{"name":"a"}}}`)
+	assert.NoError(t, err)
+	assert.True(t, res.Get("imdata").IsArray())
+	_, err = client.Delete("/api/mo/uni/tn-b", Query("rsp-subtree", "no"))
+	assert.NoError(t, err)
+	assert.False(t, gock.HasUnmatchedRequest())
+
+	changes := client.Changes.Changes
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, "POST", changes[0].Method)
+		assert.Equal(t, "/api/mo/uni/tn-a", changes[0].Path)
+		assert.Equal(t, "uni/tn-a", changes[0].Dn)
+		assert.Equal(t, "{\n  \"fvTenant\": {\n    \"attributes\": {\n      \"name\": \"a\"\n    }\n  }\n}", string(changes[0].Body))
+		assert.Equal(t, Change{Method: "DELETE", Path: "/api/mo/uni/tn-b?rsp-subtree=no", Dn: "uni/tn-b"}, changes[1])
+	}
+}
+
+// TestChangeSet tests the ChangeSet output methods.
+func TestChangeSet(t *testing.T) {
+	var cs ChangeSet
+	cs.add("POST", "/api/mo/uni", `{"fvTenant":{"attributes":{"name":"a"}}}`)
+	cs.add("POST", "/api/mo/uni", `not json`)
+	cs.add("DELETE", "/api/mo/uni/tn-b", "")
+
+	assert.Equal(t, `POST /api/mo/uni
+{
+  "fvTenant": {
+    "attributes": {
+      "name": "a"
+    }
+  }
+}
+POST /api/mo/uni
+"not json"
+DELETE /api/mo/uni/tn-b
+`, cs.String())
+
+	res := gjson.Parse(cs.JSON())
+	assert.Equal(t, "a", res.Get("changes.0.body.fvTenant.attributes.name").Str)
+	assert.False(t, res.Get("changes.2.body").Exists())
+
+	dir, err := ioutil.TempDir("", "goaci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "changes.json")
+	assert.NoError(t, cs.WriteFile(path))
+	data, _ := ioutil.ReadFile(path)
+	assert.Equal(t, cs.JSON()+"\n", string(data))
+}
+
+// TestChangeSetQuery tests merging modifier queries into the query of a recorded path.
+func TestChangeSetQuery(t *testing.T) {
+	var cs ChangeSet
+	cs.add("POST", "/api/mo/uni/tn-a?rsp-subtree=full", "{}", Query("rsp-prop-include", "config-only"))
+	cs.add("DELETE", "/api/mo/uni/tn-b/BD-b/subnet-[10.0.0.1/24]?rsp-subtree=no", "")
+	cs.add("DELETE", "/api/mo/uni/tn-c", "", Query("rsp-subtree", "no"))
+	assert.Equal(t, "/api/mo/uni/tn-a?rsp-prop-include=config-only&rsp-subtree=full", cs.Changes[0].Path)
+	assert.Equal(t, "uni/tn-a", cs.Changes[0].Dn)
+	assert.Equal(t, "/api/mo/uni/tn-b/BD-b/subnet-[10.0.0.1/24]?rsp-subtree=no", cs.Changes[1].Path)
+	assert.Equal(t, "uni/tn-b/BD-b/subnet-[10.0.0.1/24]", cs.Changes[1].Dn)
+	assert.Equal(t, "/api/mo/uni/tn-c?rsp-subtree=no", cs.Changes[2].Path)
+}
@@ -2,6 +2,7 @@
 package goaci
 
 import (
+	"crypto/rsa"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/brightpuddle/goaci/meta"
 	"github.com/tidwall/gjson"
 )
 
@@ -31,6 +33,24 @@ type Client struct {
 	LastRefresh time.Time
 	// Token is the current authentication token
 	Token string
+	// ValidatePosts indicates whether POST bodies are validated before sending.
+	// See goaci.ValidatePosts.
+	ValidatePosts bool
+	// DryRun records POST and DELETE requests in Changes instead of sending them.
+	// See goaci.DryRun.
+	DryRun bool
+	// Changes are the requests recorded in dry-run mode.
+	Changes ChangeSet
+	// CertName is the name of the user certificate for certificate authentication.
+	// See goaci.CertAuth.
+	CertName string
+	// Key is the private key of the user certificate. Requests are signed if set.
+	Key *rsa.PrivateKey
+	// SessionStore persists the session across process runs. See goaci.CacheSession.
+	SessionStore SessionStore
+	// restored indicates the session was restored from the session store and isn't verified yet,
+	// i.e. the token wasn't refreshed or replaced by a login yet.
+	restored bool
 }
 
 // NewClient creates a new ACI HTTP client.
@@ -63,12 +83,27 @@ func NewClient(url, usr, pwd string, mods ...func(*Client)) (Client, error) {
 	for _, mod := range mods {
 		mod(&client)
 	}
+	if client.ValidatePosts {
+		if err := meta.Check(); err != nil {
+			return client, err
+		}
+	}
+	if client.SessionStore != nil && client.Key == nil {
+		if err := client.restoreSession(); err != nil {
+			return client, err
+		}
+	}
 	return client, nil
 }
 
 // NewReq creates a new Req request for this client.
 func (client Client) NewReq(method, uri string, body io.Reader, mods ...func(*Req)) Req {
-	httpReq, _ := http.NewRequest(method, client.Url+uri+".json", body)
+	return client.newReq(method, client.Url+uri+".json", body, mods...)
+}
+
+// newReq creates a request for a full URL, e.g. for file transfers without the .json suffix.
+func (client Client) newReq(method, url string, body io.Reader, mods ...func(*Req)) Req {
+	httpReq, _ := http.NewRequest(method, url, body)
 	req := Req{
 		HttpReq: httpReq,
 		Refresh: true,
@@ -92,20 +127,11 @@ func RequestTimeout(x time.Duration) func(*Client) {
 //  req := client.NewReq("GET", "/api/class/fvBD", nil)
 //  res := client.Do(req)
 func (client *Client) Do(req Req) (Res, error) {
-	if req.Refresh && time.Now().Sub(client.LastRefresh) > 480*time.Second {
-		if err := client.Refresh(); err != nil {
-			return Res{}, err
-		}
-	}
-
-	httpRes, err := client.HttpClient.Do(req.HttpReq)
+	httpRes, err := client.do(req)
 	if err != nil {
 		return Res{}, err
 	}
 	defer httpRes.Body.Close()
-	if httpRes.StatusCode != http.StatusOK {
-		return Res{}, fmt.Errorf("received HTTP status %d", httpRes.StatusCode)
-	}
 	body, err := ioutil.ReadAll(httpRes.Body)
 	if err != nil {
 		return Res{}, errors.New("cannot decode response body")
@@ -113,6 +139,83 @@ func (client *Client) Do(req Req) (Res, error) {
 	return Res(gjson.ParseBytes(body)), nil
 }
 
+// do makes a request, refreshing the token if needed, and checks the HTTP status.
+// The client state only changes on a token refresh or login, so that requests with NoRefresh
+// can be made concurrently, see Client.Batch.
+// The caller closes the response body.
+func (client *Client) do(req Req) (*http.Response, error) {
+	if client.Key != nil {
+		if err := client.sign(req); err != nil {
+			return nil, err
+		}
+	} else if req.Refresh {
+		if err := client.checkRefresh(); err != nil {
+			return nil, err
+		}
+	}
+
+	httpRes, err := client.HttpClient.Do(req.HttpReq)
+	if err != nil {
+		return nil, err
+	}
+	// A restored session may no longer be valid, e.g. after an APIC logout
+	if (httpRes.StatusCode == http.StatusUnauthorized || httpRes.StatusCode == http.StatusForbidden) &&
+		client.restored && req.Refresh && client.Key == nil && client.Pwd != "" {
+		if retry, ok := retryReq(req.HttpReq); ok {
+			httpRes.Body.Close()
+			if err := client.Login(); err != nil {
+				return nil, err
+			}
+			if httpRes, err = client.HttpClient.Do(retry); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if httpRes.StatusCode != http.StatusOK {
+		httpRes.Body.Close()
+		return nil, fmt.Errorf("received HTTP status %d", httpRes.StatusCode)
+	}
+	return httpRes, nil
+}
+
+// retryReq returns a copy of a sent request to send again, without the cookies added by the
+// cookie jar. Requests with a body that can't be read again aren't retried.
+func retryReq(httpReq *http.Request) (*http.Request, bool) {
+	retry := *httpReq
+	if httpReq.Body != nil && httpReq.Body != http.NoBody {
+		if httpReq.GetBody == nil {
+			return nil, false
+		}
+		body, err := httpReq.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		retry.Body = body
+	}
+	retry.Header = make(http.Header)
+	for key, values := range httpReq.Header {
+		if key != "Cookie" {
+			retry.Header[key] = values
+		}
+	}
+	return &retry, true
+}
+
+// checkRefresh refreshes the token every 8 minutes.
+func (client *Client) checkRefresh() error {
+	if client.Key != nil || time.Now().Sub(client.LastRefresh) <= 480*time.Second {
+		return nil
+	}
+	if err := client.Refresh(); err != nil {
+		// A restored session may no longer be valid, e.g. after an APIC logout
+		if !client.restored || client.Pwd == "" {
+			return err
+		}
+		return client.Login()
+	}
+	return nil
+}
+
 // Get makes a GET request and returns a GJSON result.
 // Results will be the raw data structure as returned by the APIC, wrapped in imdata, e.g.
 //
@@ -174,18 +277,48 @@ func (client *Client) GetDn(dn string, mods ...func(*Req)) (Res, error) {
 
 // Post makes a POST request and returns a GJSON result.
 // Hint: Use the Body struct to easily create POST body data.
+// With validation enabled (see ValidatePosts), invalid bodies return a ValidationError without being sent.
+// In dry-run mode the request is recorded instead (see DryRun).
 func (client *Client) Post(path, data string, mods ...func(*Req)) (Res, error) {
+	if client.ValidatePosts && strings.HasPrefix(path, "/api/mo/") {
+		body := Body{Str: data}
+		if err := body.Validate(parentClass(path, body)...); err != nil {
+			return Res{}, err
+		}
+	}
+	if client.DryRun {
+		client.Changes.add("POST", path, data, mods...)
+		return dryRunRes, nil
+	}
 	req := client.NewReq("POST", path, strings.NewReader(data), mods...)
 	return client.Do(req)
 }
 
+// Delete makes a DELETE request, e.g. to delete an object by DN:
+//  client.Delete("/api/mo/uni/tn-mytenant")
+func (client *Client) Delete(path string, mods ...func(*Req)) (Res, error) {
+	if client.DryRun {
+		client.Changes.add("DELETE", path, "", mods...)
+		return dryRunRes, nil
+	}
+	req := client.NewReq("DELETE", path, nil, mods...)
+	return client.Do(req)
+}
+
 // Login authenticates to the APIC.
+// With certificate authentication (see CertAuth) requests are signed instead, and this does nothing.
 func (client *Client) Login() error {
-	data := fmt.Sprintf(`{"aaaUser":{"attributes":{"name":"%s","pwd":"%s"}}}`,
-		client.Usr,
-		client.Pwd,
-	)
-	res, err := client.Post("/api/aaaLogin", data, NoRefresh)
+	if client.Key != nil {
+		return nil
+	}
+	// Escaped, e.g. for login domains: apic#domain\user
+	data := Body{}.
+		Set("aaaUser.attributes.name", client.Usr).
+		Set("aaaUser.attributes.pwd", client.Pwd).
+		Str
+	// Sent directly, so that login isn't recorded in dry-run mode
+	req := client.NewReq("POST", "/api/aaaLogin", strings.NewReader(data), NoRefresh)
+	res, err := client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -195,7 +328,8 @@ func (client *Client) Login() error {
 	}
 	client.Token = res.Get("imdata.0.aaaLogin.attributes.token").Str
 	client.LastRefresh = time.Now()
-	return nil
+	client.restored = false
+	return client.saveSession()
 }
 
 // Refresh refreshes the authentication token.
@@ -203,11 +337,15 @@ func (client *Client) Login() error {
 // Refresh will be checked every request and the token will be refreshed after 8 minutes.
 // Pass goaci.NoRefresh to prevent automatic refresh handling and handle it directly instead.
 func (client *Client) Refresh() error {
+	if client.Key != nil {
+		return nil
+	}
 	res, err := client.Get("/api/aaaRefresh", NoRefresh)
 	if err != nil {
 		return err
 	}
 	client.Token = res.Get("imdata.0.aaaRefresh.attributes.token").Str
 	client.LastRefresh = time.Now()
-	return nil
+	client.restored = false
+	return client.saveSession()
 }
@@ -47,6 +47,15 @@ func TestClientLogin(t *testing.T) {
 	gock.New(testURL).Post("/api/aaaLogin.json").Reply(200)
 	assert.NoError(t, client.Login())
 
+	// Login domain and special characters are escaped
+	client.Usr = `apic#tacacs\admin`
+	client.Pwd = `p"wd`
+	gock.New(testURL).
+		Post("/api/aaaLogin.json").
+		BodyString(`^\{"aaaUser":\{"attributes":\{"name":"apic#tacacs\\\\admin","pwd":"p\\"wd"\}\}\}$`).
+		Reply(200)
+	assert.NoError(t, client.Login())
+
 	// Invalid HTTP status code
 	gock.New(testURL).Post("/api/aaaLogin.json").Reply(405)
 	assert.Error(t, client.Login())
@@ -198,3 +207,19 @@ func TestClientPost(t *testing.T) {
 	_, err = client.Post("/url", "{}")
 	assert.Error(t, err)
 }
+
+// TestClientDelete tests the Client::Delete method.
+func TestClientDelete(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+
+	// Success
+	gock.New(testURL).Delete("/api/mo/uni/tn-test.json").Reply(200)
+	_, err := client.Delete("/api/mo/uni/tn-test")
+	assert.NoError(t, err)
+
+	// HTTP error
+	gock.New(testURL).Delete("/api/mo/uni/tn-test.json").ReplyError(errors.New("fail"))
+	_, err = client.Delete("/api/mo/uni/tn-test")
+	assert.Error(t, err)
+}
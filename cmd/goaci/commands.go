@@ -0,0 +1,357 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/brightpuddle/goaci/backup"
+	"github.com/chzyer/readline"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// params are query parameters given as key=value flags.
+type params []string
+
+func (p *params) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *params) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("%q is not key=value", value)
+	}
+	*p = append(*p, value)
+	return nil
+}
+
+// outputFlags adds the output flags to a command.
+func outputFlags(fs *flag.FlagSet) func() output {
+	format := formatFlag(formatPretty)
+	fs.Var(&format, "o", "output format: raw, pretty, table or csv")
+	path := fs.String("path", "", "GJSON path to select from the result, e.g. #.*.attributes.name")
+	attrs := fs.String("attrs", "", "comma-separated attributes for table and csv output (default all)")
+	return func() output {
+		out := output{format: string(format), path: *path}
+		if *attrs != "" {
+			out.attrs = strings.Split(*attrs, ",")
+		}
+		return out
+	}
+}
+
+// pathDn returns the DN of a DN or /api/mo/ path argument.
+func pathDn(target string) (string, error) {
+	if !strings.HasPrefix(target, "/") {
+		return target, nil
+	}
+	if !strings.HasPrefix(target, "/api/mo/") {
+		return "", fmt.Errorf("%s is not a /api/mo/ path", target)
+	}
+	return strings.TrimPrefix(target, "/api/mo/"), nil
+}
+
+// apiPath returns the API path of a DN or path argument.
+func apiPath(target string) string {
+	if strings.HasPrefix(target, "/") {
+		return target
+	}
+	return "/api/mo/" + target
+}
+
+// openBackup opens a backup file, printing warnings for files that couldn't be parsed.
+func openBackup(path string) (backup.Client, error) {
+	bkup, err := backup.NewClient(path)
+	for _, warning := range bkup.Warnings {
+		fmt.Fprintln(stderr, "warning:", warning)
+	}
+	return bkup, err
+}
+
+func login(args []string) error {
+	fs := newFlagSet("login")
+	usr := fs.String("u", "admin", "username")
+	pwd := fs.String("p", "", "password (default $GOACI_PASSWORD or a prompt)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError(commands["login"].usage)
+	}
+	password := *pwd
+	if password == "" {
+		password = os.Getenv("GOACI_PASSWORD")
+	}
+	if password == "" {
+		data, err := readline.Password("Password: ")
+		if err != nil {
+			return err
+		}
+		password = string(data)
+	}
+	client, err := newClient(positional[0], *usr, password)
+	if err != nil {
+		return err
+	}
+	if err := client.Login(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Logged in to %s\n", client.Url)
+	return nil
+}
+
+// logout ends the APIC session of the last login and removes the cached session.
+// The cached session is removed even if the APIC rejects the logout, e.g. for an expired session.
+func logout(args []string) error {
+	if client, err := loadSession(); err == nil {
+		body := goaci.Body{}.Set("aaaUser.attributes.name", client.Usr).Str
+		// Sent directly, so that logout isn't recorded in dry-run mode
+		client.Do(client.NewReq("POST", "/api/aaaLogout", strings.NewReader(body), goaci.NoRefresh))
+	}
+	return removeSession()
+}
+
+func get(args []string) error {
+	fs := newFlagSet("get")
+	filter := fs.String("filter", "", "query-target-filter, e.g. eq(fvTenant.name,\"a\")")
+	target := fs.String("target", "", "query-target: self, children or subtree")
+	targetClass := fs.String("target-class", "", "target-subtree-class")
+	subtree := fs.String("subtree", "", "rsp-subtree: no, children or full (APIC only)")
+	subtreeClass := fs.String("subtree-class", "", "rsp-subtree-class (APIC only)")
+	var extra params
+	fs.Var(&extra, "query", "other query parameter as key=value (repeatable)")
+	bkupFile := fs.String("backup", "", "query a backup file instead of the APIC")
+	out := outputFlags(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 || (positional[0] != "dn" && positional[0] != "class") {
+		return usageError(commands["get"].usage)
+	}
+
+	var mods []func(*goaci.Req)
+	for k, v := range map[string]string{
+		"query-target-filter":  *filter,
+		"query-target":         *target,
+		"target-subtree-class": *targetClass,
+		"rsp-subtree":          *subtree,
+		"rsp-subtree-class":    *subtreeClass,
+	} {
+		if v != "" {
+			mods = append(mods, goaci.Query(k, v))
+		}
+	}
+	for _, kv := range extra {
+		i := strings.Index(kv, "=")
+		mods = append(mods, goaci.Query(kv[:i], kv[i+1:]))
+	}
+
+	var r goaci.Reader
+	if *bkupFile != "" {
+		bkup, err := openBackup(*bkupFile)
+		if err != nil {
+			return err
+		}
+		r = bkup
+	} else {
+		client, err := loadSession()
+		if err != nil {
+			return err
+		}
+		r = &client
+	}
+
+	var res goaci.Res
+	if positional[0] == "dn" {
+		res, err = r.GetDn(positional[1], mods...)
+		if err == nil && !res.Exists() {
+			err = fmt.Errorf("%s not found", positional[1])
+		}
+	} else {
+		res, err = r.GetClass(positional[1], mods...)
+	}
+	if err != nil {
+		return err
+	}
+	return out().write(stdout, res)
+}
+
+// readBody reads a JSON body from a file, or stdin for - or no file.
+func readBody(args []string) (string, error) {
+	var data []byte
+	var err error
+	if len(args) == 0 || args[0] == "-" {
+		data, err = ioutil.ReadAll(stdin)
+	} else {
+		data, err = ioutil.ReadFile(args[0])
+	}
+	if err != nil {
+		return "", err
+	}
+	if !gjson.ValidBytes(data) {
+		return "", errors.New("body is not valid JSON")
+	}
+	return string(data), nil
+}
+
+// objectDn returns the DN of a posted object, which is posted to its own DN or its parent DN.
+func objectDn(target, class string, attrs goaci.Res) (string, error) {
+	if dn := attrs.Get("dn").Str; dn != "" {
+		return dn, nil
+	}
+	rn := attrs.Get("rn").Str
+	if rn == "" {
+		naming := make(map[string]string)
+		attrs.ForEach(func(k, v goaci.Res) bool {
+			naming[k.Str] = v.String()
+			return true
+		})
+		var err error
+		if rn, err = backup.NewRn(class, naming); err != nil {
+			return "", err
+		}
+	}
+	if goaci.DN(target).RN() == rn {
+		return target, nil
+	}
+	return string(goaci.DN(target).Child(rn)), nil
+}
+
+// postOffline applies a POST body to a backup, as the APIC would:
+// new objects are added, existing objects are updated, and objects with status deleted are removed.
+func postOffline(bkup backup.Client, target string, obj goaci.Res) error {
+	class := goaci.ClassName(obj)
+	if class == "" {
+		return errors.New("object has no class")
+	}
+	attrs := obj.Get(class + ".attributes")
+	dn, err := objectDn(target, class, attrs)
+	if err != nil {
+		return err
+	}
+	if attrs.Get("status").Str == "deleted" {
+		return bkup.Remove(dn)
+	}
+	if _, err := bkup.GetDn(dn); goaci.IsNotFound(err) {
+		raw, _ := sjson.Delete(obj.Raw, class+".attributes.status")
+		raw, _ = sjson.Set(raw, class+".attributes.dn", dn)
+		return bkup.Add(string(goaci.DN(dn).Parent()), gjson.Parse(raw))
+	} else if err != nil {
+		return err
+	}
+	set := make(map[string]string)
+	attrs.ForEach(func(k, v goaci.Res) bool {
+		switch k.Str {
+		case "dn", "rn", "status":
+		default:
+			set[k.Str] = v.String()
+		}
+		return true
+	})
+	if len(set) > 0 {
+		if err := bkup.SetAttributes(dn, set); err != nil {
+			return err
+		}
+	}
+	for _, child := range obj.Get(class + ".children").Array() {
+		if err := postOffline(bkup, dn, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func post(args []string) error {
+	fs := newFlagSet("post")
+	bkupFile := fs.String("backup", "", "apply to a backup file instead of the APIC")
+	outFile := fs.String("out", "", "backup file to write with -backup")
+	out := outputFlags(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 1 || len(positional) > 2 {
+		return usageError(commands["post"].usage)
+	}
+	body, err := readBody(positional[1:])
+	if err != nil {
+		return err
+	}
+
+	if *bkupFile != "" {
+		if *outFile == "" {
+			return errors.New("-out is required with -backup")
+		}
+		dn, err := pathDn(positional[0])
+		if err != nil {
+			return err
+		}
+		bkup, err := openBackup(*bkupFile)
+		if err != nil {
+			return err
+		}
+		if err := postOffline(bkup, dn, gjson.Parse(body)); err != nil {
+			return err
+		}
+		return bkup.WriteFile(*outFile, backup.FormatJSON)
+	}
+
+	client, err := loadSession()
+	if err != nil {
+		return err
+	}
+	res, err := client.Post(apiPath(positional[0]), body)
+	if err != nil {
+		return err
+	}
+	return out().write(stdout, res)
+}
+
+func del(args []string) error {
+	fs := newFlagSet("delete")
+	bkupFile := fs.String("backup", "", "delete from a backup file instead of the APIC")
+	outFile := fs.String("out", "", "backup file to write with -backup")
+	out := outputFlags(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError(commands["delete"].usage)
+	}
+
+	if *bkupFile != "" {
+		if *outFile == "" {
+			return errors.New("-out is required with -backup")
+		}
+		dn, err := pathDn(positional[0])
+		if err != nil {
+			return err
+		}
+		bkup, err := openBackup(*bkupFile)
+		if err != nil {
+			return err
+		}
+		if err := bkup.Remove(dn); err != nil {
+			return err
+		}
+		return bkup.WriteFile(*outFile, backup.FormatJSON)
+	}
+
+	client, err := loadSession()
+	if err != nil {
+		return err
+	}
+	res, err := client.Delete(apiPath(positional[0]))
+	if err != nil {
+		return err
+	}
+	return out().write(stdout, res)
+}
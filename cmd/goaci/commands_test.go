@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/brightpuddle/goaci/backup"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const (
+	testURL    = "https://10.0.0.1"
+	testBackup = "../../backup/testdata/json_config.tar.gz"
+)
+
+// testRun runs a command with stdin, returning stdout.
+func testRun(t *testing.T, input string, args ...string) (string, error) {
+	var out bytes.Buffer
+	stdout = &out
+	stderr = ioutil.Discard
+	stdin = strings.NewReader(input)
+	defer func() {
+		stdout = os.Stdout
+		stderr = os.Stderr
+		stdin = os.Stdin
+	}()
+	err := run(args)
+	return out.String(), err
+}
+
+// testLogin logs in to a mocked APIC.
+func testLogin(t *testing.T) {
+	clientMods = []func(*goaci.Client){func(client *goaci.Client) {
+		gock.InterceptClient(client.HttpClient)
+	}}
+	gock.New(testURL).
+		Post("/api/aaaLogin.json").
+		Reply(200).
+		BodyString(`{"imdata":[{"aaaLogin":{"attributes":{"token":"token"}}}]}`)
+	out, err := testRun(t, "", "login", "-p", "pwd", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Logged in to "+testURL+"\n", out)
+}
+
+// TestRun tests command dispatch and usage errors.
+func TestRun(t *testing.T) {
+	_, err := testRun(t, "")
+	assert.NoError(t, err)
+	_, err = testRun(t, "", "unknown")
+	assert.Error(t, err)
+	_, err = testRun(t, "", "get", "tenant", "a")
+	assert.EqualError(t, err, "usage: goaci get dn|class <dn|class> [flags]")
+	_, err = testRun(t, "", "get", "class", "fvTenant", "-query", "invalid")
+	assert.Error(t, err)
+}
+
+// TestLive tests commands against a mocked APIC with a cached session.
+func TestLive(t *testing.T) {
+	defer testSession(t)()
+	defer gock.Off()
+	defer func() { clientMods = nil }()
+
+	_, err := testRun(t, "", "get", "class", "fvTenant")
+	assert.Error(t, err)
+
+	testLogin(t)
+	loaded, _ := loadSession()
+	assert.Equal(t, "token", loaded.Token)
+
+	// Get with query flags after positional arguments
+	gock.New(testURL).
+		Get("/api/class/fvTenant.json").
+		MatchParam("query-target-filter", `eq\(fvTenant.name,"a"\)`).
+		MatchParam("rsp-subtree", "children").
+		MatchParam("rsp-prop-include", "config-only").
+		Reply(200).
+		BodyString(`{"imdata":` + testClassRes + `}`)
+	out, err := testRun(t, "", "get", "class", "fvTenant",
+		"-filter", `eq(fvTenant.name,"a")`, "-subtree", "children",
+		"-query", "rsp-prop-include=config-only", "-o", "csv", "-attrs", "name")
+	assert.NoError(t, err)
+	assert.Equal(t, "name\na\n\"b,c\"\n", out)
+
+	gock.New(testURL).Get("/api/mo/uni/tn-x.json").Reply(200).BodyString(`{"imdata":[]}`)
+	_, err = testRun(t, "", "get", "dn", "uni/tn-x")
+	assert.EqualError(t, err, "uni/tn-x not found")
+
+	// Post from stdin and from a file
+	body := `{"fvTenant":{"attributes":{"name":"a"}}}`
+	gock.New(testURL).Post("/api/mo/uni/tn-a.json").BodyString(`^` + regexp.QuoteMeta(body) + `$`).Reply(200).BodyString(`{"imdata":[]}`)
+	_, err = testRun(t, body, "post", "uni/tn-a")
+	assert.NoError(t, err)
+
+	dir, _ := ioutil.TempDir("", "goaci")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "body.json")
+	ioutil.WriteFile(file, []byte(body), 0644)
+	gock.New(testURL).Post("/api/mo/uni.json").Reply(200).BodyString(`{"imdata":[]}`)
+	_, err = testRun(t, "", "post", "/api/mo/uni", file)
+	assert.NoError(t, err)
+
+	_, err = testRun(t, "{", "post", "uni/tn-a")
+	assert.EqualError(t, err, "body is not valid JSON")
+
+	// Delete, with the output format checked before the request
+	gock.New(testURL).Delete("/api/mo/uni/tn-a.json").Reply(200).BodyString(`{"imdata":[]}`)
+	_, err = testRun(t, "", "delete", "uni/tn-a", "-o", "tabel")
+	assert.EqualError(t, err, `invalid value "tabel" for flag -o: unknown output format "tabel"`)
+	assert.True(t, gock.IsPending())
+	_, err = testRun(t, "", "delete", "uni/tn-a", "-o", "raw")
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+
+	// Logout ends the APIC session, then removes the cached session
+	gock.New(testURL).
+		Post("/api/aaaLogout.json").
+		MatchHeader("Cookie", "APIC-cookie=token").
+		BodyString(`"name":"admin"`).
+		Reply(200)
+	_, err = testRun(t, "", "logout")
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	_, err = loadSession()
+	assert.Error(t, err)
+
+	// The cached session is removed even if the APIC rejects the logout
+	testLogin(t)
+	gock.New(testURL).Post("/api/aaaLogout.json").Reply(403)
+	_, err = testRun(t, "", "logout")
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	_, err = loadSession()
+	assert.Error(t, err)
+
+	// Nothing to log out of
+	_, err = testRun(t, "", "logout")
+	assert.NoError(t, err)
+}
+
+// TestOffline tests commands against a backup file.
+func TestOffline(t *testing.T) {
+	out, err := testRun(t, "", "get", "class", "fvTenant", "-backup", testBackup, "-o", "table", "-attrs", "name")
+	assert.NoError(t, err)
+	assert.Equal(t, "name\na\nb\n", out)
+
+	out, err = testRun(t, "", "get", "dn", "uni/tn-a", "-backup", testBackup, "-path", "*.attributes.name")
+	assert.NoError(t, err)
+	assert.Equal(t, "a\n", out)
+
+	_, err = testRun(t, "", "get", "dn", "uni/tn-x", "-backup", testBackup)
+	assert.Error(t, err)
+
+	dir, _ := ioutil.TempDir("", "goaci")
+	defer os.RemoveAll(dir)
+	dst := filepath.Join(dir, "out.tar.gz")
+
+	// Post requires an output file
+	_, err = testRun(t, "{}", "post", "uni", "-backup", testBackup)
+	assert.Error(t, err)
+
+	// Update an existing object, add a child and a new tenant posted to the parent
+	body := `{"fvTenant":{"attributes":{"name":"a","descr":"updated"},"children":[{"fvBD":{"attributes":{"name":"bd"}}}]}}`
+	_, err = testRun(t, body, "post", "uni/tn-a", "-backup", testBackup, "-out", dst)
+	assert.NoError(t, err)
+	_, err = testRun(t, `{"fvTenant":{"attributes":{"name":"c","status":"created"}}}`, "post", "uni", "-backup", dst, "-out", dst)
+	assert.NoError(t, err)
+	_, err = testRun(t, "", "delete", "/api/mo/uni/tn-b", "-backup", dst, "-out", dst)
+	assert.NoError(t, err)
+
+	bkup, err := backup.NewClient(dst)
+	if !assert.NoError(t, err) {
+		return
+	}
+	res, _ := bkup.GetDn("uni/tn-a")
+	assert.Equal(t, "updated", res.Get("fvTenant.attributes.descr").Str)
+	_, err = bkup.GetDn("uni/tn-a/BD-bd")
+	assert.NoError(t, err)
+	res, _ = bkup.GetDn("uni/tn-c")
+	assert.Equal(t, "c", res.Get("fvTenant.attributes.name").Str)
+	assert.False(t, res.Get("fvTenant.attributes.status").Exists())
+	_, err = bkup.GetDn("uni/tn-b")
+	assert.True(t, goaci.IsNotFound(err))
+
+	// Delete by status
+	_, err = testRun(t, `{"fvTenant":{"attributes":{"dn":"uni/tn-c","status":"deleted"}}}`, "post", "uni/tn-c", "-backup", dst, "-out", dst)
+	assert.NoError(t, err)
+	bkup, _ = backup.NewClient(dst)
+	_, err = bkup.GetDn("uni/tn-c")
+	assert.True(t, goaci.IsNotFound(err))
+
+	_, err = testRun(t, "", "delete", "/api/class/fvTenant", "-backup", dst, "-out", dst)
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+// Command goaci queries and configures ACI fabrics and backup files from the command line.
+//
+// Log in once; the session is cached in ~/.goaci/session.json (or $GOACI_SESSION):
+//  goaci login -u admin 10.0.0.1
+// Then query by DN or class:
+//  goaci get class fvBD -filter 'eq(fvBD.unicastRoute,"yes")' -o table -attrs dn,name
+//  goaci get dn uni/tn-a -subtree children -path '*.children.#.*.attributes.name'
+// Post from a file or stdin, and delete:
+//  goaci post uni/tn-a tenant.json
+//  goaci delete uni/tn-a
+// Browse the object model interactively, with tab completion and history:
+//  goaci shell
+// Pass -backup to run the same commands against a backup file instead.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+	stdin  io.Reader = os.Stdin
+)
+
+// command is a subcommand of the CLI.
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+// commands are the subcommands by name, set in init since commands refer to their usage.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"login":  {"login [-u user] [-p password] <apic>", login},
+		"logout": {"logout", logout},
+		"get":    {"get dn|class <dn|class> [flags]", get},
+		"post":   {"post <dn|path> [file|-] [flags]", post},
+		"delete": {"delete <dn|path> [flags]", del},
+		"shell":  {"shell [-backup file]", shellCmd},
+	}
+}
+
+func usage() {
+	fmt.Fprintln(stderr, "Usage:")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(stderr, "  goaci "+commands[name].usage)
+	}
+	fmt.Fprintln(stderr, "Run goaci <command> -h for the flags of a command.")
+}
+
+// newFlagSet creates a flag set for a command.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: goaci "+commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses flags before and after positional arguments, e.g. get class fvBD -o table.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// usageError is returned for invalid arguments.
+type usageError string
+
+func (e usageError) Error() string {
+	return "usage: goaci " + string(e)
+}
+
+func run(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		usage()
+		return nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd.run(args[1:])
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(stderr, "goaci:", strings.TrimSpace(err.Error()))
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/brightpuddle/goaci"
+)
+
+// Output formats.
+const (
+	formatRaw    = "raw"
+	formatPretty = "pretty"
+	formatTable  = "table"
+	formatCSV    = "csv"
+)
+
+// formatFlag is an output format flag, validated when the flags are parsed,
+// i.e. before any request is made.
+type formatFlag string
+
+func (f *formatFlag) String() string {
+	return string(*f)
+}
+
+func (f *formatFlag) Set(value string) error {
+	switch value {
+	case formatRaw, formatPretty, formatTable, formatCSV:
+		*f = formatFlag(value)
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q", value)
+}
+
+// output is the output format and selection of a command.
+type output struct {
+	format string
+	// path is a GJSON path applied to the result.
+	path string
+	// attrs are the attributes shown in table and CSV output.
+	attrs []string
+}
+
+// rows returns the rows of a result for table and CSV output, with the attributes of each row.
+// Objects are unwrapped from class.attributes, and other values are shown as a value column.
+func rows(res goaci.Res) []map[string]string {
+	records := []goaci.Res{res}
+	if res.IsArray() {
+		records = res.Array()
+	}
+	var rows []map[string]string
+	for _, record := range records {
+		row := make(map[string]string)
+		switch {
+		case record.Get("*.attributes").IsObject():
+			record.Get("*.attributes").ForEach(func(k, v goaci.Res) bool {
+				row[k.Str] = v.String()
+				return true
+			})
+		case record.IsObject():
+			record.ForEach(func(k, v goaci.Res) bool {
+				row[k.Str] = v.String()
+				return true
+			})
+		default:
+			row["value"] = record.String()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// columns returns the selected attributes, or all attributes with dn first.
+func (out output) columns(rows []map[string]string) []string {
+	if len(out.attrs) > 0 {
+		return out.attrs
+	}
+	seen := make(map[string]bool)
+	var columns []string
+	for _, row := range rows {
+		for k := range row {
+			if !seen[k] && k != "dn" {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Strings(columns)
+	for _, row := range rows {
+		if _, ok := row["dn"]; ok {
+			return append([]string{"dn"}, columns...)
+		}
+	}
+	return columns
+}
+
+// write writes a result in the output format.
+func (out output) write(w io.Writer, res goaci.Res) error {
+	if out.path != "" {
+		res = res.Get(out.path)
+	}
+	switch out.format {
+	case formatRaw:
+		_, err := fmt.Fprintln(w, res.Raw)
+		return err
+	case formatPretty, "":
+		if res.IsObject() || res.IsArray() {
+			_, err := fmt.Fprint(w, res.Get("@pretty").Raw)
+			return err
+		}
+		_, err := fmt.Fprintln(w, res.String())
+		return err
+	case formatTable:
+		rows := rows(res)
+		columns := out.columns(rows)
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(columns, "\t"))
+		for _, row := range rows {
+			var values []string
+			for _, column := range columns {
+				values = append(values, row[column])
+			}
+			fmt.Fprintln(tw, strings.Join(values, "\t"))
+		}
+		return tw.Flush()
+	case formatCSV:
+		rows := rows(res)
+		columns := out.columns(rows)
+		cw := csv.NewWriter(w)
+		cw.Write(columns)
+		for _, row := range rows {
+			var values []string
+			for _, column := range columns {
+				values = append(values, row[column])
+			}
+			cw.Write(values)
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown output format %q", out.format)
+}
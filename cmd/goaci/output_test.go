@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+const testClassRes = `[
+  {"fvTenant": {"attributes": {"dn": "uni/tn-a", "name": "a", "descr": "first"}}},
+  {"fvTenant": {"attributes": {"dn": "uni/tn-b", "name": "b,c"}}}
+]`
+
+// TestOutputWrite tests the output formats.
+func TestOutputWrite(t *testing.T) {
+	res := gjson.Parse(testClassRes)
+	write := func(out output) string {
+		var buf bytes.Buffer
+		assert.NoError(t, out.write(&buf, res))
+		return buf.String()
+	}
+
+	assert.Equal(t, res.Raw+"\n", write(output{format: formatRaw}))
+	assert.Equal(t, res.Get("@pretty").Raw, write(output{format: formatPretty}))
+	assert.Equal(t, "dn        descr  name\nuni/tn-a  first  a\nuni/tn-b         b,c\n",
+		write(output{format: formatTable}))
+	assert.Equal(t, "name,dn\na,uni/tn-a\n\"b,c\",uni/tn-b\n",
+		write(output{format: formatCSV, attrs: []string{"name", "dn"}}))
+
+	// Path selection
+	assert.Equal(t, "first\n", write(output{path: "0.*.attributes.descr"}))
+	assert.Equal(t, "value\na\nb,c\n", write(output{format: formatTable, path: "#.*.attributes.name"}))
+
+	var buf bytes.Buffer
+	assert.Error(t, output{format: "xml"}.write(&buf, res))
+}
+
+// TestFormatFlag tests the output format flag.
+func TestFormatFlag(t *testing.T) {
+	format := formatFlag(formatPretty)
+	assert.NoError(t, format.Set(formatCSV))
+	assert.Equal(t, formatCSV, format.String())
+	assert.EqualError(t, format.Set("tabel"), `unknown output format "tabel"`)
+	assert.Equal(t, formatCSV, format.String())
+}
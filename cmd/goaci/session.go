@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/brightpuddle/goaci"
+)
+
+// clientMods are applied to clients created by the CLI, e.g. to intercept requests in tests.
+var clientMods []func(*goaci.Client)
+
+// sessionPath returns the session file, i.e. $GOACI_SESSION or ~/.goaci/session.json.
+func sessionPath() (string, error) {
+	if path := os.Getenv("GOACI_SESSION"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".goaci", "session.json"), nil
+}
+
+// sessionStore returns the session cache of the CLI.
+func sessionStore() (goaci.SessionFile, error) {
+	path, err := sessionPath()
+	return goaci.SessionFile(path), err
+}
+
+// newClient creates a client that caches its session, so that each command doesn't log in again.
+// Sessions are saved by each login and token refresh.
+func newClient(url, usr, pwd string) (goaci.Client, error) {
+	store, err := sessionStore()
+	if err != nil {
+		return goaci.Client{}, err
+	}
+	mods := append([]func(*goaci.Client){goaci.CacheSession(store)}, clientMods...)
+	return goaci.NewClient(url, usr, pwd, mods...)
+}
+
+// loadSession creates a client from the cached session of the last login.
+func loadSession() (goaci.Client, error) {
+	path, err := sessionPath()
+	if err != nil {
+		return goaci.Client{}, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return goaci.Client{}, errors.New("not logged in, run goaci login first")
+	} else if err != nil {
+		return goaci.Client{}, err
+	}
+	// The last saved session comes first
+	var sessions []goaci.Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return goaci.Client{}, err
+	}
+	if len(sessions) == 0 {
+		return goaci.Client{}, errors.New("not logged in, run goaci login first")
+	}
+	client, err := newClient(sessions[0].Url, sessions[0].Usr, "")
+	if err != nil {
+		return goaci.Client{}, err
+	}
+	if client.Token == "" {
+		return goaci.Client{}, errors.New("session expired, run goaci login")
+	}
+	return client, nil
+}
+
+// removeSession removes the cached session.
+func removeSession() error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
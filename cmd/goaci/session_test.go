@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSession uses a session file in a temporary directory.
+// Returns a function to remove it.
+func testSession(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "goaci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("GOACI_SESSION", filepath.Join(dir, "session.json"))
+	return func() {
+		os.Unsetenv("GOACI_SESSION")
+		os.RemoveAll(dir)
+	}
+}
+
+// TestSession tests loading the session of the last login.
+func TestSession(t *testing.T) {
+	defer testSession(t)()
+
+	_, err := loadSession()
+	assert.EqualError(t, err, "not logged in, run goaci login first")
+
+	store, _ := sessionStore()
+	now := time.Now().Round(time.Second)
+	assert.NoError(t, store.Save(goaci.Session{Url: "https://10.0.0.1", Usr: "other", Token: "token1", LastRefresh: now}))
+	assert.NoError(t, store.Save(goaci.Session{Url: "https://10.0.0.1", Usr: "usr", Token: "token2", LastRefresh: now}))
+
+	path, _ := sessionPath()
+	info, err := os.Stat(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	loaded, err := loadSession()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.1", loaded.Url)
+	assert.Equal(t, "usr", loaded.Usr)
+	assert.Equal(t, "token2", loaded.Token)
+	assert.True(t, now.Equal(loaded.LastRefresh))
+
+	assert.NoError(t, store.Save(goaci.Session{Url: "https://10.0.0.1", Usr: "usr", Token: "token2", LastRefresh: now.Add(-time.Hour)}))
+	_, err = loadSession()
+	assert.EqualError(t, err, "session expired, run goaci login")
+
+	assert.NoError(t, removeSession())
+	assert.NoError(t, removeSession())
+	_, err = loadSession()
+	assert.Error(t, err)
+}
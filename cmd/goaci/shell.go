@@ -0,0 +1,387 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/brightpuddle/goaci/backup"
+	"github.com/chzyer/readline"
+)
+
+// roots are the top level DNs of the MIT listed at the shell root.
+var roots = []string{"uni", "topology", "comp"}
+
+// shellCommands are the shell commands with their help text.
+var shellCommands = map[string]string{
+	"cd":     "cd [path]\tchange to a DN, or the root without a path, e.g. cd /uni/tn-a, cd .., cd BD-web",
+	"ls":     "ls [path]\tlist child objects",
+	"cat":    "cat [path]\tshow object attributes",
+	"rels":   "rels [path]\tlist relations and their targets",
+	"follow": "follow <class>\tchange to the target of a relation, e.g. follow fvRsBd",
+	"class":  "class <class> [filter]\tquery a class, e.g. class fvBD eq(fvBD.name,\"web\")",
+	"pwd":    "pwd\tshow the current DN",
+	"help":   "help\tshow this help",
+	"exit":   "exit\tleave the shell",
+}
+
+// shell is an interactive MIT browser over a live fabric or backup file.
+type shell struct {
+	reader   goaci.Reader
+	resolver goaci.Resolver
+	// cwd is the current DN, or empty at the root.
+	cwd string
+	out io.Writer
+}
+
+func newShell(r goaci.Reader, out io.Writer) *shell {
+	return &shell{
+		reader:   r,
+		resolver: goaci.NewResolver(r),
+		out:      out,
+	}
+}
+
+// path resolves a path relative to the current DN. Paths starting with / are absolute.
+func (sh *shell) path(arg string) string {
+	dn := goaci.DN(sh.cwd)
+	if strings.HasPrefix(arg, "/") {
+		dn = ""
+		arg = strings.TrimPrefix(arg, "/")
+	}
+	for _, rn := range goaci.DN(arg).RNs() {
+		switch rn {
+		case "", ".":
+		case "..":
+			dn = dn.Parent()
+		default:
+			dn = dn.Child(rn)
+		}
+	}
+	return string(dn)
+}
+
+// object returns an object by DN.
+func (sh *shell) object(dn string) (goaci.Res, error) {
+	res, err := sh.reader.GetDn(dn)
+	if err == nil && !res.Exists() {
+		err = fmt.Errorf("%s not found", dn)
+	}
+	return res, err
+}
+
+// children returns the child objects of a DN.
+// goaci.Client.GetDn only returns the first result, so the full response is used for live fabrics.
+func (sh *shell) children(dn string) ([]goaci.Res, error) {
+	if dn == "" {
+		var objs []goaci.Res
+		for _, root := range roots {
+			if res, err := sh.reader.GetDn(root); err == nil && res.Exists() {
+				objs = append(objs, res)
+			}
+		}
+		return objs, nil
+	}
+	query := goaci.Query("query-target", "children")
+	if getter, ok := sh.reader.(interface {
+		Get(string, ...func(*goaci.Req)) (goaci.Res, error)
+	}); ok {
+		res, err := getter.Get("/api/mo/"+dn, query)
+		return res.Get("imdata").Array(), err
+	}
+	res, err := sh.reader.GetDn(dn, query)
+	return res.Array(), err
+}
+
+// class returns the class and attributes of an object.
+func class(obj goaci.Res) (string, goaci.Res) {
+	name := goaci.ClassName(obj)
+	return name, obj.Get(name + ".attributes")
+}
+
+// rns returns the RNs and classes of the children of a DN, sorted by RN.
+func (sh *shell) rns(dn string) ([][2]string, error) {
+	objs, err := sh.children(dn)
+	if err != nil {
+		return nil, err
+	}
+	var rns [][2]string
+	for _, obj := range objs {
+		name, attrs := class(obj)
+		rns = append(rns, [2]string{goaci.DN(attrs.Get("dn").Str).RN(), name})
+	}
+	sort.Slice(rns, func(i, j int) bool { return rns[i][0] < rns[j][0] })
+	return rns, nil
+}
+
+// Relation classes, i.e. <package>Rs<Name>, e.g. fvRsCtx or l3extRsEctx
+var relationClass = regexp.MustCompile(`^[a-z][a-z0-9]*Rs[A-Z]`)
+
+// relations returns the relation objects under a DN, i.e. the children with a relation class.
+func (sh *shell) relations(dn string) ([]goaci.Res, error) {
+	objs, err := sh.children(dn)
+	if err != nil {
+		return nil, err
+	}
+	var rels []goaci.Res
+	for _, obj := range objs {
+		if name, _ := class(obj); relationClass.MatchString(name) {
+			rels = append(rels, obj)
+		}
+	}
+	return rels, nil
+}
+
+func (sh *shell) prompt() string {
+	return "/" + sh.cwd + "> "
+}
+
+// exec runs a shell command line. Returns true to exit the shell.
+func (sh *shell) exec(line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false, nil
+	}
+	cmd, args := fields[0], fields[1:]
+	arg := ""
+	if len(args) > 0 {
+		arg = args[0]
+	}
+	tw := tabwriter.NewWriter(sh.out, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+
+	switch cmd {
+	case "exit", "quit":
+		return true, nil
+	case "help":
+		var names []string
+		for name := range shellCommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintln(tw, shellCommands[name])
+		}
+	case "pwd":
+		fmt.Fprintln(sh.out, "/"+sh.cwd)
+	case "cd":
+		// cd without a path returns to the root
+		dn := ""
+		if arg != "" {
+			dn = sh.path(arg)
+		}
+		if dn != "" {
+			if _, err := sh.object(dn); err != nil {
+				return false, err
+			}
+		}
+		sh.cwd = dn
+	case "ls":
+		rns, err := sh.rns(sh.path(arg))
+		if err != nil {
+			return false, err
+		}
+		for _, rn := range rns {
+			fmt.Fprintf(tw, "%s\t%s\n", rn[0], rn[1])
+		}
+	case "cat":
+		obj, err := sh.object(sh.path(arg))
+		if err != nil {
+			return false, err
+		}
+		name, attrs := class(obj)
+		fmt.Fprintln(tw, name)
+		var keys []string
+		attrs.ForEach(func(k, v goaci.Res) bool {
+			keys = append(keys, k.Str)
+			return true
+		})
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(tw, "  %s\t%s\n", key, attrs.Get(key).String())
+		}
+	case "rels":
+		rels, err := sh.relations(sh.path(arg))
+		if err != nil {
+			return false, err
+		}
+		for _, rel := range rels {
+			res, err := sh.resolver.Resolve(rel)
+			if err != nil {
+				continue
+			}
+			status := ""
+			if !res.Resolved {
+				status = "(unresolved)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t-> %s\t%s\n", res.Class, res.Name, res.Target, status)
+		}
+	case "follow":
+		if arg == "" {
+			return false, errors.New("usage: follow <class>")
+		}
+		res, err := sh.resolver.Follow(sh.cwd, arg)
+		if err != nil {
+			return false, err
+		}
+		if !res.Resolved {
+			return false, fmt.Errorf("%s target %s not found", arg, res.Target)
+		}
+		sh.cwd = res.Target
+	case "class":
+		if arg == "" {
+			return false, errors.New("usage: class <class> [filter]")
+		}
+		var mods []func(*goaci.Req)
+		if len(args) > 1 {
+			mods = append(mods, goaci.Query("query-target-filter", strings.Join(args[1:], " ")))
+		}
+		res, err := sh.reader.GetClass(arg, mods...)
+		if err != nil {
+			return false, err
+		}
+		for _, obj := range res.Array() {
+			_, attrs := class(obj)
+			fmt.Fprintln(tw, attrs.Get("dn").Str)
+		}
+	default:
+		return false, fmt.Errorf("unknown command %s, see help", cmd)
+	}
+	return false, nil
+}
+
+// complete returns the completions of the last word of a partial command line.
+func (sh *shell) complete(line string) []string {
+	fields := strings.Fields(line)
+	word := ""
+	if len(fields) > 0 && !strings.HasSuffix(line, " ") {
+		word = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+
+	var candidates []string
+	switch {
+	case len(fields) == 0:
+		for name := range shellCommands {
+			candidates = append(candidates, name)
+		}
+	case len(fields) > 1:
+	case fields[0] == "class":
+		candidates = backup.RnClasses()
+	case fields[0] == "follow":
+		rels, _ := sh.relations(sh.cwd)
+		for _, rel := range rels {
+			name, _ := class(rel)
+			candidates = append(candidates, name)
+		}
+	case fields[0] == "cd" || fields[0] == "ls" || fields[0] == "cat" || fields[0] == "rels":
+		// The directory is the word up to the last RN, e.g. BD-web/ for BD-web/subnet-[10.0.0.1/
+		dir := ""
+		if rns := goaci.DN(word).RNs(); len(rns) > 1 {
+			dir = strings.Join(rns[:len(rns)-1], "/") + "/"
+		}
+		rns, _ := sh.rns(sh.path(dir))
+		for _, rn := range rns {
+			candidates = append(candidates, dir+rn[0]+"/")
+		}
+		candidates = append(candidates, dir+"../")
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// completer adapts shell completion to readline.
+type completer struct {
+	sh *shell
+}
+
+func (c completer) Do(line []rune, pos int) ([][]rune, int) {
+	head := string(line[:pos])
+	word := head[strings.LastIndex(head, " ")+1:]
+	var suffixes [][]rune
+	for _, match := range c.sh.complete(head) {
+		suffix := match[len(word):]
+		// Paths continue with child RNs
+		if !strings.HasSuffix(suffix, "/") {
+			suffix += " "
+		}
+		suffixes = append(suffixes, []rune(suffix))
+	}
+	return suffixes, len([]rune(word))
+}
+
+func shellCmd(args []string) error {
+	fs := newFlagSet("shell")
+	bkupFile := fs.String("backup", "", "browse a backup file instead of the APIC")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return usageError(commands["shell"].usage)
+	}
+
+	var r goaci.Reader
+	if *bkupFile != "" {
+		bkup, err := openBackup(*bkupFile)
+		if err != nil {
+			return err
+		}
+		r = bkup
+	} else {
+		client, err := loadSession()
+		if err != nil {
+			return err
+		}
+		r = &client
+	}
+	sh := newShell(r, stdout)
+
+	history := ""
+	if path, err := sessionPath(); err == nil {
+		history = filepath.Join(filepath.Dir(path), "history")
+		os.MkdirAll(filepath.Dir(history), 0700)
+	}
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:       sh.prompt(),
+		HistoryFile:  history,
+		AutoComplete: completer{sh},
+	})
+	if err != nil {
+		return err
+	}
+	defer rl.Close()
+
+	for {
+		line, err := rl.Readline()
+		if err == readline.ErrInterrupt {
+			continue
+		} else if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		exit, err := sh.exec(line)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+		}
+		if exit {
+			return nil
+		}
+		rl.SetPrompt(sh.prompt())
+	}
+}
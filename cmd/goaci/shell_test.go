@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/brightpuddle/goaci/backup"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// testShell creates a shell over the test backup with a VRF, and BDs with resolved and unresolved relations.
+func testShell(t *testing.T) (*shell, *bytes.Buffer) {
+	bkup, err := backup.NewClient(testBackup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, bkup.Add("uni/tn-a", gjson.Parse(`{"fvCtx":{"attributes":{"name":"vrf"}}}`)))
+	assert.NoError(t, bkup.Add("uni/tn-a", gjson.Parse(`{"fvBD":{"attributes":{"name":"web"},"children":[
+		{"fvRsCtx":{"attributes":{"tnFvCtxName":"vrf"}}},
+		{"fvSubnet":{"attributes":{"ip":"10.0.0.1/24"}}}
+	]}}`)))
+	assert.NoError(t, bkup.Add("uni/tn-b", gjson.Parse(`{"fvBD":{"attributes":{"name":"db"},"children":[
+		{"fvRsCtx":{"attributes":{"tnFvCtxName":"missing"}}}
+	]}}`)))
+	var out bytes.Buffer
+	return newShell(bkup, &out), &out
+}
+
+// TestShellExec tests the shell commands.
+func TestShellExec(t *testing.T) {
+	sh, out := testShell(t)
+	exec := func(line string) (string, error) {
+		out.Reset()
+		_, err := sh.exec(line)
+		return out.String(), err
+	}
+
+	res, err := exec("ls")
+	assert.NoError(t, err)
+	assert.Equal(t, "uni  polUni\n", res)
+
+	_, err = exec("cd uni/tn-a")
+	assert.NoError(t, err)
+	assert.Equal(t, "/uni/tn-a> ", sh.prompt())
+	res, _ = exec("ls")
+	assert.Equal(t, "BD-web   fvBD\nctx-vrf  fvCtx\n", res)
+	res, _ = exec("cat BD-web")
+	assert.Equal(t, "fvBD\n  dn    uni/tn-a/BD-web\n  name  web\n", res)
+	res, _ = exec("rels BD-web")
+	assert.Equal(t, "fvRsCtx  vrf  -> uni/tn-a/ctx-vrf  \n", res)
+
+	// Follow a relation
+	_, err = exec("cd BD-web")
+	assert.NoError(t, err)
+	_, err = exec("follow fvRsCtx")
+	assert.NoError(t, err)
+	assert.Equal(t, "uni/tn-a/ctx-vrf", sh.cwd)
+	_, err = exec("follow")
+	assert.Error(t, err)
+
+	// Relative and absolute paths
+	_, err = exec("cd ../../tn-b/BD-db")
+	assert.NoError(t, err)
+	assert.Equal(t, "uni/tn-b/BD-db", sh.cwd)
+	res, _ = exec("rels")
+	assert.Equal(t, "fvRsCtx  missing  -> uni/tn-b/ctx-missing  (unresolved)\n", res)
+	_, err = exec("follow fvRsCtx")
+	assert.EqualError(t, err, "fvRsCtx target uni/tn-b/ctx-missing not found")
+	_, err = exec("cd /uni/tn-x")
+	assert.EqualError(t, err, "uni/tn-x not found")
+	assert.Equal(t, "uni/tn-b/BD-db", sh.cwd)
+	_, err = exec("cd")
+	assert.NoError(t, err)
+	res, _ = exec("pwd")
+	assert.Equal(t, "/\n", res)
+
+	// Class queries
+	res, _ = exec(`class fvBD eq(fvBD.name,"web")`)
+	assert.Equal(t, "uni/tn-a/BD-web\n", res)
+	_, err = exec("class")
+	assert.Error(t, err)
+
+	_, err = exec("bogus")
+	assert.Error(t, err)
+	exit, _ := sh.exec("exit")
+	assert.True(t, exit)
+}
+
+// TestShellComplete tests tab completion.
+func TestShellComplete(t *testing.T) {
+	sh, _ := testShell(t)
+	sh.cwd = "uni/tn-a"
+
+	assert.Equal(t, []string{"cat", "cd", "class"}, sh.complete("c"))
+	assert.Equal(t, []string{"BD-web/"}, sh.complete("ls B"))
+	assert.Equal(t, []string{"../tn-a/", "../tn-b/"}, sh.complete("cd ../tn-"))
+	assert.Empty(t, sh.complete("follow "))
+	assert.Contains(t, sh.complete("class fvTen"), "fvTenant")
+	assert.NotContains(t, sh.complete("class fvTen"), "fvBD")
+	assert.Empty(t, sh.complete("cat BD-web x"))
+
+	// Slashes within brackets
+	assert.Equal(t, []string{"BD-web/subnet-[10.0.0.1/24]/"}, sh.complete("cd BD-web/subnet-[10.0.0.1/"))
+	assert.Equal(t, []string{"BD-web/../", "BD-web/rsctx/", "BD-web/subnet-[10.0.0.1/24]/"}, sh.complete("cd BD-web/"))
+
+	sh.cwd = "uni/tn-a/BD-web"
+	assert.Equal(t, []string{"fvRsCtx"}, sh.complete("follow fv"))
+
+	// Readline suffixes
+	suffixes, length := completer{sh}.Do([]rune("cd ../../tn-a/B"), 15)
+	assert.Equal(t, 12, length)
+	assert.Equal(t, [][]rune{[]rune("D-web/")}, suffixes)
+	suffixes, _ = completer{sh}.Do([]rune("pw"), 2)
+	assert.Equal(t, [][]rune{[]rune("d ")}, suffixes)
+}
+
+// TestRelationClass tests matching relation classes.
+func TestRelationClass(t *testing.T) {
+	for _, class := range []string{"fvRsCtx", "vzRsSubjFiltAtt", "l3extRsEctx"} {
+		assert.True(t, relationClass.MatchString(class), class)
+	}
+	for _, class := range []string{"fvRtCtx", "fvBD", "infraRsrcPol", "RsCtx", "fvAEPgRs"} {
+		assert.False(t, relationClass.MatchString(class), class)
+	}
+}
+
+// TestShellLive tests listing children on a live fabric, where GetDn only returns the first result.
+func TestShellLive(t *testing.T) {
+	defer gock.Off()
+	client, _ := goaci.NewClient("10.0.0.1", "usr", "pwd")
+	client.LastRefresh = time.Now()
+	gock.InterceptClient(client.HttpClient)
+	gock.New(testURL).
+		Get("/api/mo/uni.json").
+		MatchParam("query-target", "children").
+		Reply(200).
+		BodyString(`{"imdata":[
+			{"fvTenant":{"attributes":{"dn":"uni/tn-b"}}},
+			{"fvTenant":{"attributes":{"dn":"uni/tn-a"}}}
+		]}`)
+	var out bytes.Buffer
+	sh := newShell(&client, &out)
+	_, err := sh.exec("ls /uni")
+	assert.NoError(t, err)
+	assert.Equal(t, "tn-a  fvTenant\ntn-b  fvTenant\n", out.String())
+}
@@ -0,0 +1,88 @@
+package goaci
+
+import (
+	"strings"
+)
+
+// DN is a distinguished name, e.g. uni/tn-a/out-x/lnodep-y/rsnodeL3OutAtt-[topology/pod-1/node-101].
+// RNs are separated by slashes, except for slashes within brackets.
+type DN string
+
+// RNs returns the relative names of the DN, e.g.
+//  goaci.DN("uni/tn-a/BD-web/subnet-[10.0.0.1/24]").RNs()
+//  // [uni tn-a BD-web subnet-[10.0.0.1/24]]
+func (dn DN) RNs() (rns []string) {
+	depth := 0
+	start := 0
+	for i, c := range dn {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case c == '/' && depth == 0:
+			rns = append(rns, string(dn[start:i]))
+			start = i + 1
+		}
+	}
+	if dn != "" {
+		rns = append(rns, string(dn[start:]))
+	}
+	return rns
+}
+
+// RN returns the last relative name of the DN, e.g. subnet-[10.0.0.1/24].
+func (dn DN) RN() string {
+	rns := dn.RNs()
+	if len(rns) == 0 {
+		return ""
+	}
+	return rns[len(rns)-1]
+}
+
+// Parent returns the parent DN, or an empty DN for a top level DN such as uni.
+func (dn DN) Parent() DN {
+	rns := dn.RNs()
+	if len(rns) < 2 {
+		return ""
+	}
+	return DN(strings.Join(rns[:len(rns)-1], "/"))
+}
+
+// Child returns the DN of a child with the given RN.
+func (dn DN) Child(rn string) DN {
+	if dn == "" {
+		return DN(rn)
+	}
+	return DN(string(dn) + "/" + rn)
+}
+
+// Ancestors returns the ancestors of the DN, from the top level DN to the parent, e.g.
+//  goaci.DN("uni/tn-a/BD-web").Ancestors()
+//  // [uni uni/tn-a]
+func (dn DN) Ancestors() (ancestors []DN) {
+	rns := dn.RNs()
+	for i := 1; i < len(rns); i++ {
+		ancestors = append(ancestors, DN(strings.Join(rns[:i], "/")))
+	}
+	return ancestors
+}
+
+// IsAncestorOf indicates whether the DN is an ancestor of another DN.
+func (dn DN) IsAncestorOf(other DN) bool {
+	if dn == "" || !strings.HasPrefix(string(other), string(dn)+"/") {
+		return false
+	}
+	// Make sure the prefix doesn't end within brackets
+	return other.RNs()[len(dn.RNs())-1] == dn.RN()
+}
+
+// ClassName returns the class name of an object, e.g. fvTenant for
+//  {"fvTenant":{"attributes":{"name":"a"}}}
+func ClassName(obj Res) (class string) {
+	obj.ForEach(func(key, _ Res) bool {
+		class = key.Str
+		return false
+	})
+	return class
+}
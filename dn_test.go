@@ -0,0 +1,36 @@
+package goaci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDN tests the DN navigation methods.
+func TestDN(t *testing.T) {
+	dn := DN("uni/tn-a/out-x/lnodep-y/rsnodeL3OutAtt-[topology/pod-1/node-101]")
+	assert.Equal(t, []string{"uni", "tn-a", "out-x", "lnodep-y", "rsnodeL3OutAtt-[topology/pod-1/node-101]"}, dn.RNs())
+	assert.Equal(t, "rsnodeL3OutAtt-[topology/pod-1/node-101]", dn.RN())
+	assert.Equal(t, DN("uni/tn-a/out-x/lnodep-y"), dn.Parent())
+	assert.Equal(t, DN(""), DN("uni").Parent())
+	assert.Empty(t, DN("").RNs())
+	assert.Equal(t, "", DN("").RN())
+	assert.Equal(t, DN("uni/tn-a"), DN("uni").Child("tn-a"))
+	assert.Equal(t, DN("uni"), DN("").Child("uni"))
+	assert.Equal(t, []DN{"uni", "uni/tn-a", "uni/tn-a/out-x", "uni/tn-a/out-x/lnodep-y"}, dn.Ancestors())
+	assert.Empty(t, DN("uni").Ancestors())
+
+	// Nested brackets
+	assert.Equal(t, []string{"uni", "tn-a", "rsx-[uni/tn-b/y-[a/b]]"}, DN("uni/tn-a/rsx-[uni/tn-b/y-[a/b]]").RNs())
+
+	assert.True(t, DN("uni/tn-a").IsAncestorOf(dn))
+	assert.False(t, DN("uni/tn-a").IsAncestorOf("uni/tn-ab"))
+	assert.False(t, DN("uni/tn-a").IsAncestorOf("uni/tn-a"))
+	assert.False(t, DN("uni/tn-a/BD-b/subnet-[10.0.0.1").IsAncestorOf("uni/tn-a/BD-b/subnet-[10.0.0.1/24]"))
+}
+
+// TestClassName tests the ClassName function.
+func TestClassName(t *testing.T) {
+	assert.Equal(t, "fvTenant", ClassName(Body{}.Set("fvTenant.attributes.name", "a").Res()))
+	assert.Equal(t, "", ClassName(Body{}.Res()))
+}
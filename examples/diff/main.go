@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/brightpuddle/goaci/backup"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the diff as JSON")
+	flag.Parse()
+	if flag.NArg() != 2 {
+		fmt.Println("usage: diff [-json] before.tar.gz after.tar.gz")
+		return
+	}
+
+	before, err := backup.NewClient(flag.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	after, err := backup.NewClient(flag.Arg(1))
+	if err != nil {
+		panic(err)
+	}
+
+	diff := backup.Diff(before, after, backup.IgnoreVolatile)
+	if *asJSON {
+		fmt.Println(diff.JSON())
+		return
+	}
+	fmt.Print(diff)
+	// + fvTenant uni/tn-new
+	// - fvBD uni/tn-a/BD-old
+	// ~ fvBD uni/tn-a/BD-web
+	//     descr: "before" -> "after"
+}
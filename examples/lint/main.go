@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/brightpuddle/goaci/backup"
+	"github.com/brightpuddle/goaci/lint"
+)
+
+func main() {
+	format := flag.String("format", "text", "report format: text, json or junit")
+	minSeverity := flag.String("severity", "info", "minimum severity: info, warning or error")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("usage: lint [-format text|json|junit] [-severity info|warning|error] config.tar.gz")
+		return
+	}
+	sev, err := lint.ParseSeverity(*minSeverity)
+	if err != nil {
+		panic(err)
+	}
+
+	// Lint runs offline against a backup, or against a live fabric with a goaci.Client
+	bkup, err := backup.NewClient(flag.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	report := lint.Run(bkup, lint.Rules, lint.MinSeverity(sev))
+
+	switch *format {
+	case "json":
+		fmt.Println(report.JSON())
+	case "junit":
+		fmt.Print(report.JUnit())
+	default:
+		fmt.Print(report)
+	}
+	if !report.Passed() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,128 @@
+package goaci
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+
+	"github.com/tidwall/gjson"
+)
+
+// Progress reports file transfer progress in bytes, with the total size or -1 if unknown, e.g.
+//  client.Download("/files/...", f, goaci.Progress(func(done, total int64) {
+//    fmt.Printf("\r%d/%d", done, total)
+//  }))
+func Progress(fn func(done, total int64)) func(*Req) {
+	return func(req *Req) {
+		req.Progress = fn
+	}
+}
+
+// progressReader reports progress while reading.
+type progressReader struct {
+	r     io.Reader
+	done  int64
+	total int64
+	fn    func(done, total int64)
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.done += int64(n)
+	if n > 0 && p.fn != nil {
+		p.fn(p.done, p.total)
+	}
+	return n, err
+}
+
+// size returns the remaining size of a reader, or -1 if unknown.
+func size(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
+
+// Download streams a file from the APIC, e.g. from the /files/ paths, to a writer.
+// The path is used as is, i.e. without the .json suffix of API requests.
+// Returns the number of bytes written.
+func (client *Client) Download(path string, w io.Writer, mods ...func(*Req)) (int64, error) {
+	req := client.newReq("GET", client.Url+path, nil, mods...)
+	httpRes, err := client.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer httpRes.Body.Close()
+	return io.Copy(w, &progressReader{r: httpRes.Body, total: httpRes.ContentLength, fn: req.Progress})
+}
+
+// DownloadFile downloads a file from the APIC to a local file, e.g. to analyze a fresh backup:
+//  client.DownloadFile("/files/...", "backup.tar.gz")
+//  bkup, _ := backup.NewClient("backup.tar.gz")
+// The local file is removed if the download fails.
+func (client *Client) DownloadFile(path, dst string, mods ...func(*Req)) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = client.Download(path, f, mods...)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+	}
+	return err
+}
+
+// Upload uploads a file as a multipart form POST, e.g. a firmware image:
+//  f, _ := os.Open("image.bin")
+//  res, err := client.Upload("/path/to/upload", "image.bin", f)
+// The file is streamed rather than read into memory. Uploads are not supported in dry-run mode.
+func (client *Client) Upload(path, fileName string, r io.Reader, mods ...func(*Req)) (Res, error) {
+	if client.DryRun {
+		return Res{}, errors.New("uploads are not supported in dry-run mode")
+	}
+	pr, pw := io.Pipe()
+	// Unblocks the writer if the request fails before the body is read
+	defer pr.Close()
+	form := multipart.NewWriter(pw)
+	req := client.newReq("POST", client.Url+path, pr, mods...)
+	req.HttpReq.Header.Set("Content-Type", form.FormDataContentType())
+
+	src := &progressReader{r: r, total: size(r), fn: req.Progress}
+	go func() {
+		part, err := form.CreateFormFile("file", fileName)
+		if err == nil {
+			_, err = io.Copy(part, src)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	httpRes, err := client.do(req)
+	if err != nil {
+		return Res{}, err
+	}
+	defer httpRes.Body.Close()
+	body, err := ioutil.ReadAll(httpRes.Body)
+	if err != nil {
+		return Res{}, errors.New("cannot decode response body")
+	}
+	return Res(gjson.ParseBytes(body)), nil
+}
@@ -0,0 +1,114 @@
+package goaci
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// TestClientDownload tests the Client::Download method.
+func TestClientDownload(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+
+	// Success, without the .json suffix
+	gock.New(testURL).
+		Get("/files/exports/backup.tar.gz$").
+		Reply(200).
+		SetHeader("Content-Length", "7").
+		BodyString("content")
+	var buf bytes.Buffer
+	var done, total int64
+	n, err := client.Download("/files/exports/backup.tar.gz", &buf, Progress(func(d, t int64) {
+		done, total = d, t
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), n)
+	assert.Equal(t, "content", buf.String())
+	assert.Equal(t, int64(7), done)
+	assert.Equal(t, int64(7), total)
+
+	// Invalid HTTP status code
+	gock.New(testURL).Get("/files/missing").Reply(404)
+	_, err = client.Download("/files/missing", &buf)
+	assert.Error(t, err)
+
+	// HTTP error
+	gock.New(testURL).Get("/files/fail").ReplyError(errors.New("fail"))
+	_, err = client.Download("/files/fail", &buf)
+	assert.Error(t, err)
+}
+
+// TestClientDownloadFile tests the Client::DownloadFile method.
+func TestClientDownloadFile(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	dir, _ := ioutil.TempDir("", "goaci")
+	defer os.RemoveAll(dir)
+
+	gock.New(testURL).Get("/files/a").Reply(200).BodyString("content")
+	dst := filepath.Join(dir, "a")
+	assert.NoError(t, client.DownloadFile("/files/a", dst))
+	data, _ := ioutil.ReadFile(dst)
+	assert.Equal(t, "content", string(data))
+
+	// The file is removed on failure
+	gock.New(testURL).Get("/files/b").Reply(404)
+	dst = filepath.Join(dir, "b")
+	assert.Error(t, client.DownloadFile("/files/b", dst))
+	_, err := os.Stat(dst)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// TestClientUpload tests the Client::Upload method.
+func TestClientUpload(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+
+	// Success
+	gock.New(testURL).
+		Post("/upload$").
+		MatchHeader("Content-Type", "^multipart/form-data; boundary=").
+		BodyString(`name="file"; filename="image.bin"(?s).*image data`).
+		Reply(200).
+		BodyString(`{"imdata":[]}`)
+	var done, total int64
+	res, err := client.Upload("/upload", "image.bin", strings.NewReader("image data"), Progress(func(d, t int64) {
+		done, total = d, t
+	}))
+	assert.NoError(t, err)
+	assert.True(t, res.Get("imdata").IsArray())
+	assert.Equal(t, int64(10), done)
+	assert.Equal(t, int64(10), total)
+	assert.True(t, gock.IsDone())
+
+	// HTTP error
+	gock.New(testURL).Post("/upload").ReplyError(errors.New("fail"))
+	_, err = client.Upload("/upload", "image.bin", strings.NewReader("image data"))
+	assert.Error(t, err)
+
+	// Dry run
+	DryRun(&client)
+	_, err = client.Upload("/upload", "image.bin", strings.NewReader("image data"))
+	assert.Error(t, err)
+}
+
+// TestSize tests reader size detection for upload progress.
+func TestSize(t *testing.T) {
+	assert.Equal(t, int64(4), size(strings.NewReader("data")))
+	assert.Equal(t, int64(-1), size(ErrReader{}))
+
+	f, _ := ioutil.TempFile("", "goaci")
+	defer os.Remove(f.Name())
+	defer f.Close()
+	f.WriteString("data")
+	f.Seek(1, 0)
+	assert.Equal(t, int64(3), size(f))
+}
@@ -0,0 +1,179 @@
+package goaci
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Fleet runs the same query across multiple fabrics, e.g. for fleet-wide reports:
+//  fleet := goaci.NewFleet(map[string]goaci.Reader{"lab": &lab, "prod": &prod})
+//  res := fleet.GetClass("fabricNode")
+//  for _, node := range res.Res.Array() {
+//    fmt.Println(node.Get("fabricNode.attributes.fabric"), node.Get("fabricNode.attributes.dn"))
+//  }
+// Fabrics are queried concurrently. A failing fabric doesn't fail the others; see FleetResult.Errors.
+type Fleet struct {
+	// Fabrics are the clients by fabric name.
+	Fabrics map[string]Reader
+	// Concurrency is the maximum number of fabrics queried at once.
+	Concurrency int
+	// Tag is the attribute added to each object with the fabric name.
+	Tag string
+}
+
+// NewFleet creates a fleet of fabrics. Clients are logged in by the caller.
+func NewFleet(fabrics map[string]Reader, mods ...func(*Fleet)) Fleet {
+	fleet := Fleet{
+		Fabrics:     fabrics,
+		Concurrency: 8,
+		Tag:         "fabric",
+	}
+	for _, mod := range mods {
+		mod(&fleet)
+	}
+	return fleet
+}
+
+// FleetConcurrency modifies the number of fabrics queried at once from the default of 8.
+func FleetConcurrency(n int) func(*Fleet) {
+	return func(fleet *Fleet) {
+		fleet.Concurrency = n
+	}
+}
+
+// FabricTag modifies the attribute with the fabric name from the default of fabric.
+func FabricTag(attr string) func(*Fleet) {
+	return func(fleet *Fleet) {
+		fleet.Tag = attr
+	}
+}
+
+// FleetResult is the result of a fleet query.
+type FleetResult struct {
+	// Res are the objects of all fabrics, tagged with their fabric name, e.g.
+	//  [{"fabricNode":{"attributes":{"dn":"topology/pod-1/node-101","fabric":"lab"}}}]
+	// Objects are ordered by fabric name.
+	Res Res
+	// Fabrics are the tagged objects by fabric name, for the fabrics without error.
+	Fabrics map[string]Res
+	// Errors are the errors by fabric name.
+	Errors map[string]error
+}
+
+// Err returns an error summarizing the failed fabrics, or nil if all fabrics succeeded.
+func (result FleetResult) Err() error {
+	if len(result.Errors) == 0 {
+		return nil
+	}
+	var names []string
+	for name := range result.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var msgs []string
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", name, result.Errors[name]))
+	}
+	return fmt.Errorf("%d of %d fabrics failed\n%s",
+		len(names), len(names)+len(result.Fabrics), strings.Join(msgs, "\n"))
+}
+
+// objects returns the objects of a result, i.e. the elements of an array or imdata, or a single object.
+func objects(res Res) []Res {
+	switch {
+	case res.IsArray():
+		return res.Array()
+	case res.Get("imdata").Exists():
+		return res.Get("imdata").Array()
+	case res.IsObject():
+		return []Res{res}
+	}
+	return nil
+}
+
+// tag adds the fabric name to the attributes of each object.
+func (fleet Fleet) tag(name string, res Res) Res {
+	var raw []string
+	for _, obj := range objects(res) {
+		tagged, err := sjson.Set(obj.Raw, ClassName(obj)+".attributes."+fleet.Tag, name)
+		if err != nil {
+			tagged = obj.Raw
+		}
+		raw = append(raw, tagged)
+	}
+	return gjson.Parse("[" + strings.Join(raw, ",") + "]")
+}
+
+// Query runs a query on each fabric, e.g.
+//  fleet.Query(func(r goaci.Reader) (goaci.Res, error) {
+//    return r.GetClass("faultInst", goaci.Query("query-target-filter", `eq(faultInst.severity,"critical")`))
+//  })
+// Results are lists of objects, imdata responses or single objects. Missing DNs are empty results.
+func (fleet Fleet) Query(query func(Reader) (Res, error)) FleetResult {
+	var names []string
+	for name := range fleet.Fabrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	concurrency := fleet.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]Res, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res, err := query(fleet.Fabrics[name])
+			if IsNotFound(err) {
+				res, err = Res{}, nil
+			}
+			results[i], errs[i] = res, err
+		}(i, name)
+	}
+	wg.Wait()
+
+	result := FleetResult{
+		Fabrics: make(map[string]Res),
+		Errors:  make(map[string]error),
+	}
+	var raw []string
+	for i, name := range names {
+		if errs[i] != nil {
+			result.Errors[name] = errs[i]
+			continue
+		}
+		tagged := fleet.tag(name, results[i])
+		result.Fabrics[name] = tagged
+		for _, obj := range tagged.Array() {
+			raw = append(raw, obj.Raw)
+		}
+	}
+	result.Res = gjson.Parse("[" + strings.Join(raw, ",") + "]")
+	return result
+}
+
+// GetClass queries a class on each fabric.
+func (fleet Fleet) GetClass(class string, mods ...func(*Req)) FleetResult {
+	return fleet.Query(func(r Reader) (Res, error) {
+		return r.GetClass(class, mods...)
+	})
+}
+
+// GetDn queries a DN on each fabric.
+func (fleet Fleet) GetDn(dn string, mods ...func(*Req)) FleetResult {
+	return fleet.Query(func(r Reader) (Res, error) {
+		return r.GetDn(dn, mods...)
+	})
+}
@@ -0,0 +1,126 @@
+package goaci
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// fakeReader returns a fixed result and tracks concurrent calls.
+type fakeReader struct {
+	res     string
+	err     error
+	mu      *sync.Mutex
+	active  *int
+	maxSeen *int
+}
+
+func (r fakeReader) get() (Res, error) {
+	if r.mu != nil {
+		r.mu.Lock()
+		*r.active++
+		if *r.active > *r.maxSeen {
+			*r.maxSeen = *r.active
+		}
+		r.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		r.mu.Lock()
+		*r.active--
+		r.mu.Unlock()
+	}
+	return Body{Str: r.res}.Res(), r.err
+}
+
+func (r fakeReader) GetClass(class string, mods ...func(*Req)) (Res, error) {
+	return r.get()
+}
+
+func (r fakeReader) GetDn(dn string, mods ...func(*Req)) (Res, error) {
+	return r.get()
+}
+
+// notFound is a not found error as returned by backup.Client.
+type notFound struct{}
+
+func (notFound) Error() string  { return "not found" }
+func (notFound) NotFound() bool { return true }
+
+// TestFleetGetClass tests querying a class across fabrics.
+func TestFleetGetClass(t *testing.T) {
+	defer gock.Off()
+	lab := testClient()
+	prod := testClient()
+	prod.Url = "https://10.0.0.2"
+	down := testClient()
+	down.Url = "https://10.0.0.3"
+
+	gock.New(testURL).
+		Get("/api/class/fabricNode.json").
+		MatchParam("query-target-filter", `eq\(fabricNode.role,"leaf"\)`).
+		Reply(200).
+		BodyString(`{"imdata":[{"fabricNode":{"attributes":{"dn":"topology/pod-1/node-101"}}}]}`)
+	gock.New("https://10.0.0.2").
+		Get("/api/class/fabricNode.json").
+		Reply(200).
+		BodyString(`{"imdata":[
+			{"fabricNode":{"attributes":{"dn":"topology/pod-1/node-101"}}},
+			{"fabricNode":{"attributes":{"dn":"topology/pod-1/node-102"}}}
+		]}`)
+	gock.New("https://10.0.0.3").Get("/api/class/fabricNode.json").Reply(500)
+
+	fleet := NewFleet(map[string]Reader{"prod": &prod, "lab": &lab, "down": &down})
+	res := fleet.GetClass("fabricNode", Query("query-target-filter", `eq(fabricNode.role,"leaf")`))
+	assert.True(t, gock.IsDone())
+
+	nodes := res.Res.Array()
+	if assert.Len(t, nodes, 3) {
+		assert.Equal(t, "lab", nodes[0].Get("fabricNode.attributes.fabric").Str)
+		assert.Equal(t, "prod", nodes[1].Get("fabricNode.attributes.fabric").Str)
+		assert.Equal(t, "topology/pod-1/node-102", nodes[2].Get("fabricNode.attributes.dn").Str)
+	}
+	assert.Len(t, res.Fabrics["prod"].Array(), 2)
+	assert.NotContains(t, res.Fabrics, "down")
+	assert.EqualError(t, res.Errors["down"], "received HTTP status 500")
+	assert.EqualError(t, res.Err(), "1 of 3 fabrics failed\ndown: received HTTP status 500")
+}
+
+// TestFleetQuery tests result handling and modifiers.
+func TestFleetQuery(t *testing.T) {
+	fleet := NewFleet(map[string]Reader{
+		"a": fakeReader{res: `{"topSystem":{"attributes":{"dn":"topology/pod-1/node-1/sys"}}}`},
+		"b": fakeReader{res: `{"imdata":[{"topSystem":{"attributes":{"dn":"topology/pod-1/node-2/sys"}}}]}`},
+		"c": fakeReader{err: notFound{}},
+		"d": fakeReader{},
+	}, FabricTag("site"))
+
+	res := fleet.GetDn("topology/pod-1/node-1/sys")
+	assert.NoError(t, res.Err())
+	assert.Equal(t, []string{"a", "b"}, []string{
+		res.Res.Get("0.topSystem.attributes.site").Str,
+		res.Res.Get("1.topSystem.attributes.site").Str,
+	})
+	assert.Len(t, res.Res.Array(), 2)
+	assert.Len(t, res.Fabrics, 4)
+	assert.Empty(t, res.Fabrics["c"].Array())
+
+	res = fleet.Query(func(r Reader) (Res, error) {
+		return Res{}, errors.New("fail")
+	})
+	assert.Len(t, res.Errors, 4)
+	assert.Empty(t, res.Res.Array())
+
+	// Bounded concurrency
+	var mu sync.Mutex
+	active, maxSeen := 0, 0
+	fabrics := make(map[string]Reader)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		fabrics[name] = fakeReader{res: `[]`, mu: &mu, active: &active, maxSeen: &maxSeen}
+	}
+	res = NewFleet(fabrics, FleetConcurrency(2)).GetClass("fabricNode")
+	assert.NoError(t, res.Err())
+	assert.Equal(t, 2, maxSeen)
+}
@@ -3,9 +3,11 @@ module github.com/brightpuddle/goaci
 go 1.12
 
 require (
+	github.com/chzyer/readline v1.5.1
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/stretchr/testify v1.3.0
 	github.com/tidwall/gjson v1.14.0
 	github.com/tidwall/sjson v1.2.4
 	gopkg.in/h2non/gock.v1 v1.0.15
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,181 @@
+// Package lint audits ACI configuration against best-practice rules.
+// Rules run against the common read API, i.e. a live fabric (goaci.Client) or a backup file (backup.Client), e.g.
+//  bkup, _ := backup.NewClient("config.tar.gz")
+//  report := lint.Run(bkup, lint.Rules)
+//  fmt.Print(report)
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/brightpuddle/goaci"
+)
+
+// Res is an alias of goaci.Res.
+type Res = goaci.Res
+
+// Severity is the severity of a finding.
+type Severity int
+
+// Severities in increasing order.
+const (
+	Info Severity = iota + 1
+	Warning
+	Error
+)
+
+var severityNames = map[Severity]string{
+	Info:    "info",
+	Warning: "warning",
+	Error:   "error",
+}
+
+// String returns the severity name, e.g. warning.
+func (sev Severity) String() string {
+	if name, ok := severityNames[sev]; ok {
+		return name
+	}
+	return fmt.Sprintf("severity(%d)", int(sev))
+}
+
+// MarshalText encodes the severity as its name in JSON.
+func (sev Severity) MarshalText() ([]byte, error) {
+	return []byte(sev.String()), nil
+}
+
+// ParseSeverity parses a severity name, e.g. warning.
+func ParseSeverity(name string) (Severity, error) {
+	for sev, s := range severityNames {
+		if strings.EqualFold(s, name) {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid severity %s", name)
+}
+
+// Finding is a rule violation.
+type Finding struct {
+	// Rule is the ID of the rule reporting the finding.
+	Rule string `json:"rule"`
+	// Severity defaults to the rule severity.
+	Severity Severity `json:"severity"`
+	// Dn is the DN of the offending object.
+	Dn      string `json:"dn"`
+	Message string `json:"message"`
+}
+
+// Rule is a configuration check.
+// Implement this interface for custom rules, or use NewRule.
+type Rule interface {
+	// ID is the unique rule identifier, e.g. bd-no-subnet.
+	ID() string
+	Description() string
+	// Severity is the default severity of findings.
+	Severity() Severity
+	// Check returns the findings for the configuration.
+	Check(r goaci.Reader) ([]Finding, error)
+}
+
+// funcRule is a Rule backed by a check function.
+type funcRule struct {
+	id          string
+	description string
+	severity    Severity
+	check       func(goaci.Reader) ([]Finding, error)
+}
+
+// ID returns the rule ID.
+func (rule funcRule) ID() string {
+	return rule.id
+}
+
+// Description returns the rule description.
+func (rule funcRule) Description() string {
+	return rule.description
+}
+
+// Severity returns the default severity of findings.
+func (rule funcRule) Severity() Severity {
+	return rule.severity
+}
+
+// Check runs the check function.
+func (rule funcRule) Check(r goaci.Reader) ([]Finding, error) {
+	return rule.check(r)
+}
+
+// NewRule creates a rule from a check function, e.g.
+//  rule := lint.NewRule("tenant-descr", "Tenants have a description", lint.Info,
+//    func(r goaci.Reader) (findings []lint.Finding, err error) {
+//      tenants, err := r.GetClass("fvTenant")
+//      ...
+//    })
+//  report := lint.Run(bkup, append(lint.Rules, rule))
+func NewRule(id, description string, severity Severity, check func(goaci.Reader) ([]Finding, error)) Rule {
+	return funcRule{id, description, severity, check}
+}
+
+// Options are the options for running rules.
+type Options struct {
+	// MinSeverity is the minimum severity of reported findings.
+	MinSeverity Severity
+	// Skip is the set of rule IDs to skip.
+	Skip map[string]bool
+}
+
+// MinSeverity only reports findings of at least the given severity.
+func MinSeverity(sev Severity) func(*Options) {
+	return func(opts *Options) {
+		opts.MinSeverity = sev
+	}
+}
+
+// Skip skips the rules with the given IDs.
+func Skip(ids ...string) func(*Options) {
+	return func(opts *Options) {
+		for _, id := range ids {
+			opts.Skip[id] = true
+		}
+	}
+}
+
+// Run runs rules against a configuration and returns the report.
+// A rule failing with an error doesn't stop the other rules; the error is recorded in the report.
+func Run(r goaci.Reader, rules []Rule, mods ...func(*Options)) Report {
+	opts := Options{Skip: make(map[string]bool)}
+	for _, mod := range mods {
+		mod(&opts)
+	}
+	var report Report
+	for _, rule := range rules {
+		if opts.Skip[rule.ID()] {
+			continue
+		}
+		result := Result{
+			Rule:        rule.ID(),
+			Description: rule.Description(),
+			Severity:    rule.Severity(),
+			Findings:    []Finding{},
+		}
+		findings, err := rule.Check(r)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		for _, finding := range findings {
+			finding.Rule = rule.ID()
+			if finding.Severity == 0 {
+				finding.Severity = rule.Severity()
+			}
+			if finding.Severity >= opts.MinSeverity {
+				result.Findings = append(result.Findings, finding)
+			}
+		}
+		sort.SliceStable(result.Findings, func(i, j int) bool {
+			return result.Findings[i].Dn < result.Findings[j].Dn
+		})
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
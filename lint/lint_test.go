@@ -0,0 +1,100 @@
+package lint
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// notFound is a not found error for the test reader.
+type notFound string
+
+func (err notFound) Error() string  { return string(err) + " not found" }
+func (err notFound) NotFound() bool { return true }
+
+// testReader is an in-memory goaci.Reader of flat objects.
+type testReader []Res
+
+// newTestReader parses objects in the class.attributes format.
+func newTestReader(objs ...string) testReader {
+	var r testReader
+	for _, obj := range objs {
+		r = append(r, gjson.Parse(obj))
+	}
+	return r
+}
+
+// GetClass returns the objects of a class.
+func (r testReader) GetClass(class string, mods ...func(*goaci.Req)) (Res, error) {
+	var raws []string
+	for _, obj := range r {
+		if obj.Get(class).Exists() {
+			raws = append(raws, obj.Raw)
+		}
+	}
+	if len(raws) == 0 {
+		return Res{}, notFound(class)
+	}
+	return gjson.Parse("[" + strings.Join(raws, ",") + "]"), nil
+}
+
+// GetDn returns an object by DN.
+func (r testReader) GetDn(dn string, mods ...func(*goaci.Req)) (Res, error) {
+	for _, obj := range r {
+		if obj.Get("*.attributes.dn").Str == dn {
+			return obj, nil
+		}
+	}
+	return Res{}, notFound(dn)
+}
+
+// TestRun tests the Run function.
+func TestRun(t *testing.T) {
+	r := newTestReader(`{"fvTenant":{"attributes":{"dn":"uni/tn-b"}}}`, `{"fvTenant":{"attributes":{"dn":"uni/tn-a"}}}`)
+	rules := []Rule{
+		NewRule("tenants", "List tenants", Info, func(r goaci.Reader) (findings []Finding, err error) {
+			tenants, _ := r.GetClass("fvTenant")
+			for _, tenant := range tenants.Array() {
+				findings = append(findings, Finding{Dn: tenant.Get("fvTenant.attributes.dn").Str})
+			}
+			findings = append(findings, Finding{Dn: "uni/tn-c", Severity: Error})
+			return findings, nil
+		}),
+		NewRule("broken", "Fails", Error, func(goaci.Reader) ([]Finding, error) {
+			return nil, errors.New("fail")
+		}),
+	}
+
+	// Default severity and sorting
+	report := Run(r, rules)
+	findings := report.Findings()
+	if assert.Len(t, findings, 3) {
+		assert.Equal(t, Finding{Rule: "tenants", Severity: Info, Dn: "uni/tn-a"}, findings[0])
+		assert.Equal(t, Error, findings[2].Severity)
+	}
+	assert.Equal(t, "fail", report.Results[1].Error)
+	assert.False(t, report.Passed())
+
+	// Minimum severity
+	report = Run(r, rules, MinSeverity(Error))
+	assert.Len(t, report.Findings(), 1)
+
+	// Skipped rules
+	report = Run(r, rules, Skip("broken"), MinSeverity(Error), Skip("tenants"))
+	assert.Empty(t, report.Results)
+	assert.True(t, report.Passed())
+}
+
+// TestParseSeverity tests the ParseSeverity function.
+func TestParseSeverity(t *testing.T) {
+	sev, err := ParseSeverity("Warning")
+	assert.NoError(t, err)
+	assert.Equal(t, Warning, sev)
+	assert.Equal(t, "warning", sev.String())
+	_, err = ParseSeverity("fatal")
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package lint
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Result is the outcome of a single rule.
+type Result struct {
+	Rule        string    `json:"rule"`
+	Description string    `json:"description"`
+	Severity    Severity  `json:"severity"`
+	Findings    []Finding `json:"findings"`
+	// Error is set if the rule failed to run.
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a lint run, with a result per rule.
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// Findings returns the findings of all rules.
+func (report Report) Findings() (findings []Finding) {
+	for _, result := range report.Results {
+		findings = append(findings, result.Findings...)
+	}
+	return findings
+}
+
+// Passed indicates that there are no findings and no rule errors.
+func (report Report) Passed() bool {
+	for _, result := range report.Results {
+		if len(result.Findings) > 0 || result.Error != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// JSON returns the report as indented JSON.
+func (report Report) JSON() string {
+	data, _ := json.MarshalIndent(report, "", "  ")
+	return string(data)
+}
+
+// String returns the report as human-readable text, e.g.
+//  warning bd-no-subnet uni/tn-a/BD-web: BD has no subnets
+//  error   unresolved-relation uni/tn-a/BD-web/rsctx: fvRsCtx target uni/tn-a/ctx-vrf not found
+//  2 findings, 0 rule errors
+func (report Report) String() string {
+	var text strings.Builder
+	var count, errors int
+	for _, result := range report.Results {
+		for _, finding := range result.Findings {
+			count++
+			fmt.Fprintf(&text, "%-7s %s %s: %s\n", finding.Severity, finding.Rule, finding.Dn, finding.Message)
+		}
+		if result.Error != "" {
+			errors++
+			fmt.Fprintf(&text, "rule %s failed: %s\n", result.Rule, result.Error)
+		}
+	}
+	fmt.Fprintf(&text, "%d findings, %d rule errors\n", count, errors)
+	return text.String()
+}
+
+// JUnit types
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+// JUnit returns the report as JUnit XML for CI systems, with a test case per rule.
+// Rules with findings are failures and rules that failed to run are errors.
+func (report Report) JUnit() string {
+	suite := junitSuite{Name: "goaci-lint"}
+	for _, result := range report.Results {
+		tc := junitCase{Name: result.Rule, ClassName: "lint"}
+		if len(result.Findings) > 0 {
+			var lines []string
+			for _, finding := range result.Findings {
+				lines = append(lines, fmt.Sprintf("%s %s: %s", finding.Severity, finding.Dn, finding.Message))
+			}
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%s: %d findings", result.Description, len(result.Findings)),
+				Type:    result.Severity.String(),
+				Text:    strings.Join(lines, "\n"),
+			}
+			suite.Failures++
+		}
+		if result.Error != "" {
+			tc.Error = &junitFailure{Message: result.Error, Type: "error"}
+			suite.Errors++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+	data, _ := xml.MarshalIndent(suite, "", "  ")
+	return xml.Header + string(data) + "\n"
+}
@@ -0,0 +1,55 @@
+package lint
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// testReport returns a report with a failed rule, a passed rule and a broken rule.
+func testReport() Report {
+	return Report{Results: []Result{
+		{
+			Rule:        "bd-no-subnet",
+			Description: "Bridge domains have a subnet",
+			Severity:    Warning,
+			Findings:    []Finding{{Rule: "bd-no-subnet", Severity: Warning, Dn: "uni/tn-a/BD-web", Message: "BD has no subnets"}},
+		},
+		{Rule: "empty-tenant", Severity: Info, Findings: []Finding{}},
+		{Rule: "broken", Severity: Error, Findings: []Finding{}, Error: "fail"},
+	}}
+}
+
+// TestReportJSON tests the Report::JSON method.
+func TestReportJSON(t *testing.T) {
+	res := gjson.Parse(testReport().JSON())
+	assert.Equal(t, "warning", res.Get("results.0.findings.0.severity").Str)
+	assert.Equal(t, "uni/tn-a/BD-web", res.Get("results.0.findings.0.dn").Str)
+	assert.True(t, res.Get("results.1.findings").IsArray())
+	assert.Equal(t, "fail", res.Get("results.2.error").Str)
+}
+
+// TestReportString tests the Report::String method.
+func TestReportString(t *testing.T) {
+	assert.Equal(t,
+		"warning bd-no-subnet uni/tn-a/BD-web: BD has no subnets\n"+
+			"rule broken failed: fail\n"+
+			"1 findings, 1 rule errors\n",
+		testReport().String())
+}
+
+// TestReportJUnit tests the Report::JUnit method.
+func TestReportJUnit(t *testing.T) {
+	var suite junitSuite
+	assert.NoError(t, xml.Unmarshal([]byte(testReport().JUnit()), &suite))
+	assert.Equal(t, 3, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, 1, suite.Errors)
+	if assert.Len(t, suite.Cases, 3) {
+		assert.Equal(t, "warning uni/tn-a/BD-web: BD has no subnets", suite.Cases[0].Failure.Text)
+		assert.Nil(t, suite.Cases[1].Failure)
+		assert.Equal(t, "fail", suite.Cases[2].Error.Message)
+	}
+}
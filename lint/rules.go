@@ -0,0 +1,171 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/brightpuddle/goaci"
+)
+
+// Rules is the built-in rule set.
+var Rules = []Rule{
+	NewRule("bd-no-subnet", "Bridge domains have a subnet", Warning, bdNoSubnet),
+	NewRule("epg-no-domain", "EPGs are associated with a domain", Warning, epgNoDomain),
+	NewRule("contract-no-subject", "Contracts have a subject", Warning, contractNoSubject),
+	NewRule("unused-filter", "Filters are used by a contract", Info, unusedFilter),
+	NewRule("empty-tenant", "Tenants contain configuration", Info, emptyTenant),
+	NewRule("unresolved-relation", "Named relations resolve to an existing target", Error, unresolvedRelation),
+}
+
+// systemTenants are the tenants present on every fabric.
+var systemTenants = map[string]bool{
+	"uni/tn-common": true,
+	"uni/tn-infra":  true,
+	"uni/tn-mgmt":   true,
+}
+
+// objects returns the objects of a class, treating a missing class as empty.
+func objects(r goaci.Reader, class string) ([]Res, error) {
+	res, err := r.GetClass(class)
+	if goaci.IsNotFound(err) {
+		return nil, nil
+	}
+	return res.Array(), err
+}
+
+// dnOf returns the DN of an object.
+func dnOf(obj Res, class string) string {
+	return obj.Get(class + ".attributes.dn").Str
+}
+
+// withoutChild returns findings for the objects of a class without a child of another class.
+func withoutChild(r goaci.Reader, class, childClass, message string) ([]Finding, error) {
+	parents, err := objects(r, class)
+	if err != nil {
+		return nil, err
+	}
+	children, err := objects(r, childClass)
+	if err != nil {
+		return nil, err
+	}
+	hasChild := make(map[goaci.DN]bool)
+	for _, child := range children {
+		hasChild[goaci.DN(dnOf(child, childClass)).Parent()] = true
+	}
+	var findings []Finding
+	for _, parent := range parents {
+		if dn := dnOf(parent, class); !hasChild[goaci.DN(dn)] {
+			findings = append(findings, Finding{Dn: dn, Message: message})
+		}
+	}
+	return findings, nil
+}
+
+// bdNoSubnet finds bridge domains without subnets.
+// Layer 2 only BDs, i.e. without unicast routing, are left out.
+func bdNoSubnet(r goaci.Reader) ([]Finding, error) {
+	findings, err := withoutChild(r, "fvBD", "fvSubnet", "BD has no subnets")
+	if err != nil {
+		return nil, err
+	}
+	bds, err := objects(r, "fvBD")
+	if err != nil {
+		return nil, err
+	}
+	l2 := make(map[string]bool)
+	for _, bd := range bds {
+		if bd.Get("fvBD.attributes.unicastRoute").Str == "no" {
+			l2[dnOf(bd, "fvBD")] = true
+		}
+	}
+	var routed []Finding
+	for _, finding := range findings {
+		if !l2[finding.Dn] {
+			routed = append(routed, finding)
+		}
+	}
+	return routed, nil
+}
+
+// epgNoDomain finds EPGs without a domain association.
+func epgNoDomain(r goaci.Reader) ([]Finding, error) {
+	return withoutChild(r, "fvAEPg", "fvRsDomAtt", "EPG has no domain association")
+}
+
+// contractNoSubject finds contracts without subjects.
+func contractNoSubject(r goaci.Reader) ([]Finding, error) {
+	return withoutChild(r, "vzBrCP", "vzSubj", "contract has no subjects")
+}
+
+// unusedFilter finds filters not referenced by any contract subject or taboo.
+func unusedFilter(r goaci.Reader) ([]Finding, error) {
+	filters, err := objects(r, "vzFilter")
+	if err != nil {
+		return nil, err
+	}
+	resolutions, err := goaci.NewResolver(r).ResolveAll("vzRsSubjFiltAtt", "vzRsFiltAtt", "vzRsDenyRule")
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool)
+	for _, res := range resolutions {
+		used[res.Target] = true
+	}
+	var findings []Finding
+	for _, filter := range filters {
+		dn := dnOf(filter, "vzFilter")
+		if !used[dn] && dn != "uni/tn-common/flt-default" {
+			findings = append(findings, Finding{Dn: dn, Message: "filter is not used"})
+		}
+	}
+	return findings, nil
+}
+
+// emptyTenant finds user tenants without application profiles, networking or contracts.
+func emptyTenant(r goaci.Reader) ([]Finding, error) {
+	tenants, err := objects(r, "fvTenant")
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool)
+	for _, class := range []string{"fvAp", "fvBD", "fvCtx", "l3extOut", "l2extOut", "vzBrCP", "vzFilter"} {
+		objs, err := objects(r, class)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			used[string(goaci.DN(dnOf(obj, class)).Parent())] = true
+		}
+	}
+	var findings []Finding
+	for _, tenant := range tenants {
+		dn := dnOf(tenant, "fvTenant")
+		if !used[dn] && !systemTenants[dn] {
+			findings = append(findings, Finding{Dn: dn, Message: "tenant is empty"})
+		}
+	}
+	return findings, nil
+}
+
+// unresolvedRelation finds named relations without an existing target.
+// Relations without a name resolve to a default object, so a missing default is only a warning.
+func unresolvedRelation(r goaci.Reader) ([]Finding, error) {
+	resolutions, err := goaci.NewResolver(r).ResolveAll()
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, res := range resolutions {
+		if res.Resolved {
+			continue
+		}
+		finding := Finding{
+			Dn:      res.Source,
+			Message: fmt.Sprintf("%s target %s not found", res.Class, res.Target),
+		}
+		if res.Name == "default" {
+			finding.Severity = Warning
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
@@ -0,0 +1,53 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testConfig is a configuration with one violation of each built-in rule.
+var testConfig = newTestReader(
+	`{"fvTenant":{"attributes":{"dn":"uni/tn-common"}}}`,
+	`{"vzFilter":{"attributes":{"dn":"uni/tn-common/flt-default","name":"default"}}}`,
+	`{"fvTenant":{"attributes":{"dn":"uni/tn-a"}}}`,
+	`{"fvTenant":{"attributes":{"dn":"uni/tn-empty"}}}`,
+	`{"fvCtx":{"attributes":{"dn":"uni/tn-a/ctx-vrf","name":"vrf"}}}`,
+	`{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-web","name":"web"}}}`,
+	`{"fvRsCtx":{"attributes":{"dn":"uni/tn-a/BD-web/rsctx","tnFvCtxName":"vrf"}}}`,
+	`{"fvSubnet":{"attributes":{"dn":"uni/tn-a/BD-web/subnet-[10.0.0.1/24]","ip":"10.0.0.1/24"}}}`,
+	`{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-db","name":"db"}}}`,
+	`{"fvRsCtx":{"attributes":{"dn":"uni/tn-a/BD-db/rsctx","tnFvCtxName":"missing"}}}`,
+	`{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-l2","name":"l2","unicastRoute":"no"}}}`,
+	`{"fvRsCtx":{"attributes":{"dn":"uni/tn-a/BD-l2/rsctx","tnFvCtxName":"vrf"}}}`,
+	`{"fvAp":{"attributes":{"dn":"uni/tn-a/ap-app","name":"app"}}}`,
+	`{"fvAEPg":{"attributes":{"dn":"uni/tn-a/ap-app/epg-web","name":"web"}}}`,
+	`{"fvRsDomAtt":{"attributes":{"dn":"uni/tn-a/ap-app/epg-web/rsdomAtt-[uni/phys-phys]","tDn":"uni/phys-phys"}}}`,
+	`{"fvAEPg":{"attributes":{"dn":"uni/tn-a/ap-app/epg-db","name":"db"}}}`,
+	`{"vzBrCP":{"attributes":{"dn":"uni/tn-a/brc-web","name":"web"}}}`,
+	`{"vzSubj":{"attributes":{"dn":"uni/tn-a/brc-web/subj-s","name":"s"}}}`,
+	`{"vzRsSubjFiltAtt":{"attributes":{"dn":"uni/tn-a/brc-web/subj-s/rssubjFiltAtt-http","tnVzFilterName":"http"}}}`,
+	`{"vzBrCP":{"attributes":{"dn":"uni/tn-a/brc-db","name":"db"}}}`,
+	`{"vzFilter":{"attributes":{"dn":"uni/tn-a/flt-http","name":"http"}}}`,
+	`{"vzFilter":{"attributes":{"dn":"uni/tn-a/flt-old","name":"old"}}}`,
+)
+
+// TestRules tests the built-in rules.
+func TestRules(t *testing.T) {
+	report := Run(testConfig, Rules)
+	found := make(map[string][]string)
+	for _, result := range report.Results {
+		assert.Empty(t, result.Error)
+		for _, finding := range result.Findings {
+			found[finding.Rule] = append(found[finding.Rule], finding.Dn)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"bd-no-subnet":        {"uni/tn-a/BD-db"},
+		"epg-no-domain":       {"uni/tn-a/ap-app/epg-db"},
+		"contract-no-subject": {"uni/tn-a/brc-db"},
+		"unused-filter":       {"uni/tn-a/flt-old"},
+		"empty-tenant":        {"uni/tn-empty"},
+		"unresolved-relation": {"uni/tn-a/BD-db/rsctx"},
+	}, found)
+}
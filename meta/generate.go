@@ -0,0 +1,8 @@
+package meta
+
+// Place aci-meta.json files downloaded from the APIC in this directory as aci-meta-<version>.json,
+// then run go generate ./meta to generate the models and update the backup RN table and the
+// typed structs in the mo package. No metadata file is included, so no model is generated by default:
+// without one, register the metadata at runtime with LoadFile. backup/rns.go is the hand-maintained
+// base RN table; the generator merges the RN formats of the metadata into it.
+//go:generate go run ./metagen -in aci-meta-*.json -rns ../backup/rns.go -mo ../mo/classes.go
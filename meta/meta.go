@@ -0,0 +1,230 @@
+// Package meta provides APIC model metadata by class, i.e. RN formats, naming properties,
+// containment and property definitions.
+// Models are generated from the aci-meta.json file served by the APIC at /acimeta/aci-meta.json
+// (see generate.go), or loaded at runtime with LoadFile. Several ACI versions can be registered side by side.
+//
+// No model is generated in this repository, so the metadata must be registered before use, e.g.
+//  meta.LoadFile("aci-meta-5.2.1g.json", "")
+// Functions that need the metadata return ErrNoModels otherwise: goaci.Body.Validate,
+// goaci.NewClient with goaci.ValidatePosts, backup.Client.RestoreBody, backup.Drift, backup.MatchDn for RNs
+// shared by several classes, and backup.NewClient with backup.EncryptSecure or backup.Redact.
+package meta
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Property is a property definition.
+type Property struct {
+	// Configurable indicates that the property can be set on POST.
+	// Other properties are read-only.
+	Configurable bool
+	// Values are the valid values of an enumerated property.
+	Values []string
+	// Ranges are the valid numeric ranges, e.g. 0-65535 for a port, which may be set along with Values.
+	Ranges []Range
+	// Default is the default value, e.g. for properties left out of a configuration export.
+	Default string
+	// Secure indicates a secret, e.g. a password or key, which the APIC leaves out of queries
+	// and unencrypted configuration exports.
+	Secure bool
+}
+
+// Range is a numeric range of property values, inclusive.
+type Range struct {
+	Min, Max int64
+}
+
+// Valid indicates whether a value is valid for the property, i.e. one of the values or a number in
+// one of the ranges. Properties without values or ranges take any value.
+func (prop Property) Valid(value string) bool {
+	if len(prop.Values) == 0 && len(prop.Ranges) == 0 {
+		return true
+	}
+	for _, v := range prop.Values {
+		if v == value {
+			return true
+		}
+	}
+	if n, err := strconv.ParseInt(value, 0, 64); err == nil {
+		for _, r := range prop.Ranges {
+			if n >= r.Min && n <= r.Max {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Class is a class definition.
+type Class struct {
+	Name string
+	// RnFormat is the RN template, e.g. BD-{name} or subnet-{[ip]}.
+	// Bracketed properties are converted from the APIC form [{ip}].
+	RnFormat string
+	// Naming are the naming properties, i.e. the properties in the RN.
+	Naming []string
+	// Parents are the classes this class can be contained by.
+	Parents []string
+	// Children are the classes this class can contain.
+	Children []string
+	// Configurable indicates that the class can be created on POST.
+	Configurable bool
+	Properties   map[string]Property
+}
+
+// ReadOnly returns the names of the read-only properties of the class.
+func (class Class) ReadOnly() (names []string) {
+	for name, prop := range class.Properties {
+		if !prop.Configurable {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Secure returns the names of the secure properties of the class.
+func (class Class) Secure() (names []string) {
+	for name, prop := range class.Properties {
+		if prop.Secure {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasParent indicates whether the class can be contained by a parent class.
+func (class Class) HasParent(parent string) bool {
+	for _, p := range class.Parents {
+		if p == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// Model is the metadata for an ACI version.
+type Model struct {
+	// Version is the ACI version, e.g. 5.2(1g).
+	Version string
+	Classes map[string]Class
+}
+
+var (
+	mu     sync.RWMutex
+	models = make(map[string]*Model)
+)
+
+// Register makes a model available to Lookup, replacing a model of the same version.
+// Generated models are registered on init.
+func Register(model *Model) {
+	mu.Lock()
+	defer mu.Unlock()
+	models[model.Version] = model
+}
+
+// Unregister removes the model for an ACI version.
+func Unregister(version string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(models, version)
+}
+
+// ErrNoModels is returned by functions that need the metadata when no model is registered.
+var ErrNoModels = errors.New("no metadata models registered, see meta.LoadFile")
+
+// Check returns ErrNoModels if no model is registered.
+func Check() error {
+	if len(Versions()) == 0 {
+		return ErrNoModels
+	}
+	return nil
+}
+
+// Versions returns the registered ACI versions, oldest first.
+func Versions() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	versions := make([]string, 0, len(models))
+	for version := range models {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return CompareVersions(versions[i], versions[j]) < 0
+	})
+	return versions
+}
+
+// Get returns the model for an ACI version.
+func Get(version string) (*Model, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	model, ok := models[version]
+	return model, ok
+}
+
+// Latest returns the model for the newest registered ACI version.
+func Latest() (*Model, bool) {
+	versions := Versions()
+	if len(versions) == 0 {
+		return nil, false
+	}
+	return Get(versions[len(versions)-1])
+}
+
+// Lookup returns a class definition from the newest registered model defining the class.
+func Lookup(class string) (Class, bool) {
+	versions := Versions()
+	for i := len(versions) - 1; i >= 0; i-- {
+		model, _ := Get(versions[i])
+		if c, ok := model.Classes[class]; ok {
+			return c, true
+		}
+	}
+	return Class{}, false
+}
+
+// versionRuns splits a version into runs of digits and letters, e.g. 5.2(1g) into 5 2 1 g.
+func versionRuns(version string) (runs []string) {
+	run := ""
+	for _, c := range version + "." {
+		switch {
+		case run != "" && unicode.IsDigit(c) == unicode.IsDigit(rune(run[0])) && (unicode.IsDigit(c) || unicode.IsLetter(c)):
+			run += string(c)
+		default:
+			if run != "" {
+				runs = append(runs, run)
+			}
+			run = ""
+			if unicode.IsDigit(c) || unicode.IsLetter(c) {
+				run = string(c)
+			}
+		}
+	}
+	return runs
+}
+
+// CompareVersions compares ACI versions, e.g. 4.2(7f) < 5.2(1g) < 5.2(10a).
+// The result is negative, zero or positive like strings.Compare.
+func CompareVersions(a, b string) int {
+	ra := versionRuns(a)
+	rb := versionRuns(b)
+	for i := 0; i < len(ra) && i < len(rb); i++ {
+		na, errA := strconv.Atoi(ra[i])
+		nb, errB := strconv.Atoi(rb[i])
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			return na - nb
+		case (errA != nil || errB != nil) && ra[i] != rb[i]:
+			return strings.Compare(ra[i], rb[i])
+		}
+	}
+	return len(ra) - len(rb)
+}
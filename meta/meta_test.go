@@ -0,0 +1,81 @@
+package meta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompareVersions tests the CompareVersions function.
+func TestCompareVersions(t *testing.T) {
+	assert.True(t, CompareVersions("4.2(7f)", "5.2(1g)") < 0)
+	assert.True(t, CompareVersions("5.2(1g)", "5.2(10a)") < 0)
+	assert.True(t, CompareVersions("5.2(1h)", "5.2(1g)") > 0)
+	assert.True(t, CompareVersions("5.2", "5.2(1g)") < 0)
+	assert.Equal(t, 0, CompareVersions("5.2(1g)", "5.2(1g)"))
+}
+
+// TestRegistry tests model registration and lookup across versions.
+func TestRegistry(t *testing.T) {
+	older := &Model{Version: "4.2(7f)", Classes: map[string]Class{
+		"fvBD":  {Name: "fvBD", RnFormat: "BD-{name}"},
+		"fvOld": {Name: "fvOld", RnFormat: "old-{name}"},
+	}}
+	newer := &Model{Version: "5.2(1g)", Classes: map[string]Class{
+		"fvBD": {Name: "fvBD", RnFormat: "BD-{name}", Naming: []string{"name"}},
+	}}
+	Register(newer)
+	Register(older)
+	defer Unregister(older.Version)
+	defer Unregister(newer.Version)
+
+	assert.Equal(t, []string{"4.2(7f)", "5.2(1g)"}, Versions())
+	latest, ok := Latest()
+	assert.True(t, ok)
+	assert.Equal(t, "5.2(1g)", latest.Version)
+	model, ok := Get("4.2(7f)")
+	assert.True(t, ok)
+	assert.Equal(t, older, model)
+
+	// Newest version first, falling back to older versions
+	class, ok := Lookup("fvBD")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"name"}, class.Naming)
+	class, ok = Lookup("fvOld")
+	assert.True(t, ok)
+	assert.Equal(t, "old-{name}", class.RnFormat)
+	_, ok = Lookup("fvMissing")
+	assert.False(t, ok)
+
+	Unregister("5.2(1g)")
+	assert.Equal(t, []string{"4.2(7f)"}, Versions())
+}
+
+// TestClass tests the Class methods.
+func TestClass(t *testing.T) {
+	class := Class{
+		Parents: []string{"fvTenant"},
+		Properties: map[string]Property{
+			"name":  {Configurable: true},
+			"pcTag": {},
+			"dn":    {},
+		},
+	}
+	assert.Equal(t, []string{"dn", "pcTag"}, class.ReadOnly())
+	assert.True(t, class.HasParent("fvTenant"))
+	assert.False(t, class.HasParent("fvAp"))
+}
+
+// TestPropertyValid tests the Property.Valid method.
+func TestPropertyValid(t *testing.T) {
+	port := Property{Values: []string{"unspecified", "http"}, Ranges: []Range{{Min: 0, Max: 65535}}}
+	assert.True(t, port.Valid("http"))
+	assert.True(t, port.Valid("8080"))
+	assert.True(t, port.Valid("0"))
+	assert.False(t, port.Valid("65536"))
+	assert.False(t, port.Valid("-1"))
+	assert.False(t, port.Valid("web"))
+	assert.True(t, Property{}.Valid("anything"))
+	assert.False(t, Property{Values: []string{"yes", "no"}}.Valid("8080"))
+	assert.True(t, Property{Ranges: []Range{{Min: 1, Max: 4094}}}.Valid("100"))
+}
@@ -0,0 +1,465 @@
+// Command metagen generates Go metadata models from APIC aci-meta.json files.
+// Download the metadata from an APIC, e.g.
+//  curl -k https://apic/acimeta/aci-meta.json -o meta/aci-meta-5.2.1g.json
+// and run go generate ./meta. Each file is written to a model_<version>.go file registering
+// the model, the RN templates are merged into the backup package RN table and the fields of the
+// typed structs in the mo package are updated from the class properties.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brightpuddle/goaci/meta"
+)
+
+func main() {
+	in := flag.String("in", "aci-meta-*.json", "metadata files (glob pattern)")
+	out := flag.String("out", ".", "output directory for the generated models")
+	version := flag.String("version", "", "ACI version, if not in the metadata file (single file only)")
+	rns := flag.String("rns", "", "RN table file to update, e.g. ../backup/rns.go")
+	mo := flag.String("mo", "", "typed managed object file to update, e.g. ../mo/classes.go")
+	flag.Parse()
+
+	files, err := filepath.Glob(*in)
+	if err != nil {
+		fail(err)
+	}
+	if len(files) == 0 {
+		fmt.Printf("metagen: no files matching %s\n", *in)
+		return
+	}
+	if *version != "" && len(files) > 1 {
+		fail(fmt.Errorf("-version requires a single metadata file"))
+	}
+
+	var models []*meta.Model
+	var sources []string
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			fail(err)
+		}
+		model, err := meta.Parse(data, *version)
+		if err != nil {
+			fail(fmt.Errorf("%s: %v", file, err))
+		}
+		src, err := modelSource(model, filepath.Base(file))
+		if err != nil {
+			fail(err)
+		}
+		dst := filepath.Join(*out, modelFile(model.Version))
+		if err := ioutil.WriteFile(dst, src, 0644); err != nil {
+			fail(err)
+		}
+		models = append(models, model)
+		sources = append(sources, filepath.ToSlash(file))
+	}
+
+	if *rns != "" {
+		existing, err := ioutil.ReadFile(*rns)
+		if err != nil {
+			fail(err)
+		}
+		src, err := rnSource(existing, models, sources)
+		if err != nil {
+			fail(fmt.Errorf("%s: %v", *rns, err))
+		}
+		if err := ioutil.WriteFile(*rns, src, 0644); err != nil {
+			fail(err)
+		}
+	}
+
+	if *mo != "" {
+		existing, err := ioutil.ReadFile(*mo)
+		if err != nil {
+			fail(err)
+		}
+		src, err := moSource(existing, models, sources)
+		if err != nil {
+			fail(fmt.Errorf("%s: %v", *mo, err))
+		}
+		if err := ioutil.WriteFile(*mo, src, 0644); err != nil {
+			fail(err)
+		}
+	}
+}
+
+// fail prints an error and exits.
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "metagen:", err)
+	os.Exit(1)
+}
+
+var nonAlnum = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// modelFile returns the file name for a model, e.g. model_5_2_1g.go for 5.2(1g).
+func modelFile(version string) string {
+	return "model_" + strings.Trim(nonAlnum.ReplaceAllString(version, "_"), "_") + ".go"
+}
+
+// stringSlice returns a Go []string literal.
+func stringSlice(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+// modelSource generates the Go source registering a model.
+func modelSource(model *meta.Model, source string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by metagen from %s; DO NOT EDIT.\n\n", source)
+	b.WriteString("package meta\n\n")
+	b.WriteString("func init() {\n")
+	fmt.Fprintf(&b, "Register(&Model{\nVersion: %q,\nClasses: map[string]Class{\n", model.Version)
+
+	names := make([]string, 0, len(model.Classes))
+	for name := range model.Classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		class := model.Classes[name]
+		fmt.Fprintf(&b, "%q: {\nName: %q,\n", name, name)
+		if class.RnFormat != "" {
+			fmt.Fprintf(&b, "RnFormat: %q,\n", class.RnFormat)
+		}
+		if len(class.Naming) > 0 {
+			fmt.Fprintf(&b, "Naming: %s,\n", stringSlice(class.Naming))
+		}
+		if len(class.Parents) > 0 {
+			fmt.Fprintf(&b, "Parents: %s,\n", stringSlice(class.Parents))
+		}
+		if len(class.Children) > 0 {
+			fmt.Fprintf(&b, "Children: %s,\n", stringSlice(class.Children))
+		}
+		if class.Configurable {
+			b.WriteString("Configurable: true,\n")
+		}
+		props := make([]string, 0, len(class.Properties))
+		for prop := range class.Properties {
+			props = append(props, prop)
+		}
+		sort.Strings(props)
+		b.WriteString("Properties: map[string]Property{\n")
+		for _, prop := range props {
+			p := class.Properties[prop]
+			var fields []string
+			if p.Configurable {
+				fields = append(fields, "Configurable: true")
+			}
+			if len(p.Values) > 0 {
+				fields = append(fields, "Values: "+stringSlice(p.Values))
+			}
+			if len(p.Ranges) > 0 {
+				ranges := make([]string, len(p.Ranges))
+				for i, r := range p.Ranges {
+					ranges[i] = fmt.Sprintf("{Min: %d, Max: %d}", r.Min, r.Max)
+				}
+				fields = append(fields, "Ranges: []Range{"+strings.Join(ranges, ", ")+"}")
+			}
+			if p.Default != "" {
+				fields = append(fields, "Default: "+strconv.Quote(p.Default))
+			}
+			if p.Secure {
+				fields = append(fields, "Secure: true")
+			}
+			fmt.Fprintf(&b, "%q: {%s},\n", prop, strings.Join(fields, ", "))
+		}
+		b.WriteString("},\n},\n")
+	}
+	b.WriteString("},\n})\n}\n")
+	return format.Source(b.Bytes())
+}
+
+// readRnTable reads the rnTemplates map literal from an existing RN table file.
+func readRnTable(src []byte) (pkg string, table map[string]string, err error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	table = make(map[string]string)
+	found := false
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || spec.Names[0].Name != "rnTemplates" || len(spec.Values) != 1 {
+			return true
+		}
+		lit, ok := spec.Values[0].(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		found = true
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, keyOk := kv.Key.(*ast.BasicLit)
+			value, valueOk := kv.Value.(*ast.BasicLit)
+			if !keyOk || !valueOk {
+				continue
+			}
+			k, _ := strconv.Unquote(key.Value)
+			v, _ := strconv.Unquote(value.Value)
+			table[k] = v
+		}
+		return false
+	})
+	if !found {
+		return "", nil, fmt.Errorf("rnTemplates not found")
+	}
+	return file.Name.Name, table, nil
+}
+
+// rnSource generates the RN table, merging the RN formats of the models into the existing table.
+// Newer models take precedence, and classes missing from the metadata are kept.
+// The sources are the metadata files named in the header.
+func rnSource(existing []byte, models []*meta.Model, sources []string) ([]byte, error) {
+	pkg, table, err := readRnTable(existing)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(models, func(i, j int) bool {
+		return meta.CompareVersions(models[i].Version, models[j].Version) < 0
+	})
+	for _, model := range models {
+		for name, class := range model.Classes {
+			if class.RnFormat != "" {
+				table[name] = class.RnFormat
+			}
+		}
+	}
+
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by metagen from %s; DO NOT EDIT.\n\n", strings.Join(sources, ", "))
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("// RN templates by class\n\n")
+	b.WriteString("var rnTemplates = map[string]string{\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "%q: %q,\n", name, table[name])
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// moField is a field of a typed managed object struct.
+type moField struct {
+	name     string // Go field name
+	prop     string // APIC property name
+	readOnly bool
+}
+
+// moType is a typed managed object struct.
+type moType struct {
+	name   string
+	class  string
+	doc    string
+	fields []moField
+}
+
+// readMoTypes reads the typed managed object structs and their class names from an existing mo source file.
+func readMoTypes(src []byte) (pkg string, types []*moType, err error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+	byName := make(map[string]*moType)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				spec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				st, ok := spec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				typ := &moType{name: spec.Name.Name, doc: strings.TrimSpace(decl.Doc.Text())}
+				for _, field := range st.Fields.List {
+					if len(field.Names) != 1 || field.Tag == nil {
+						continue
+					}
+					tag, _ := strconv.Unquote(field.Tag.Value)
+					prop := strings.Split(reflect.StructTag(tag).Get("json"), ",")[0]
+					if prop == "" {
+						continue
+					}
+					typ.fields = append(typ.fields, moField{
+						name:     field.Names[0].Name,
+						prop:     prop,
+						readOnly: reflect.StructTag(tag).Get("mo") == "ro",
+					})
+				}
+				byName[typ.name] = typ
+				types = append(types, typ)
+			}
+		case *ast.FuncDecl:
+			if decl.Name.Name != "ClassName" || decl.Recv == nil || len(decl.Recv.List) != 1 || decl.Body == nil {
+				continue
+			}
+			recv, ok := decl.Recv.List[0].Type.(*ast.Ident)
+			if !ok || byName[recv.Name] == nil || len(decl.Body.List) != 1 {
+				continue
+			}
+			ret, ok := decl.Body.List[0].(*ast.ReturnStmt)
+			if !ok || len(ret.Results) != 1 {
+				continue
+			}
+			if lit, ok := ret.Results[0].(*ast.BasicLit); ok {
+				byName[recv.Name].class, _ = strconv.Unquote(lit.Value)
+			}
+		}
+	}
+	var named []*moType
+	for _, typ := range types {
+		if typ.class != "" {
+			named = append(named, typ)
+		}
+	}
+	if len(named) == 0 {
+		return "", nil, fmt.Errorf("no managed object types found")
+	}
+	return file.Name.Name, named, nil
+}
+
+// Properties common to all classes that are left out of the typed structs
+var moImplicit = map[string]bool{
+	"childAction": true,
+	"lcOwn":       true,
+	"modTs":       true,
+	"rn":          true,
+	"uid":         true,
+}
+
+// moFields merges the fields of a typed struct with the properties of its class.
+// The dn is first, followed by the naming properties, the other properties and the status.
+// Fields for properties missing from the metadata are kept.
+func moFields(typ *moType, class meta.Class) []moField {
+	existing := make(map[string]moField)
+	for _, field := range typ.fields {
+		existing[field.prop] = field
+	}
+	props := make(map[string]bool)
+	for prop := range class.Properties {
+		if !moImplicit[prop] {
+			props[prop] = true
+		}
+	}
+	for prop := range existing {
+		props[prop] = true
+	}
+	delete(props, "dn")
+	var order []string
+	for _, prop := range class.Naming {
+		if props[prop] {
+			order = append(order, prop)
+			delete(props, prop)
+		}
+	}
+	status := props["status"]
+	delete(props, "status")
+	var rest []string
+	for prop := range props {
+		rest = append(rest, prop)
+	}
+	sort.Strings(rest)
+	order = append([]string{"dn"}, append(order, rest...)...)
+	if status {
+		order = append(order, "status")
+	}
+
+	fields := make([]moField, len(order))
+	for i, prop := range order {
+		field, ok := existing[prop]
+		if !ok {
+			field = moField{name: strings.ToUpper(prop[:1]) + prop[1:], prop: prop}
+		}
+		if p, ok := class.Properties[prop]; ok && prop != "dn" {
+			field.readOnly = !p.Configurable
+		}
+		fields[i] = field
+	}
+	return fields
+}
+
+// moSource generates the typed managed object structs, updating the fields of the structs in the
+// existing source from the class properties. Newer models take precedence. The sources are the
+// metadata files named in the header. Structs for classes missing from the metadata are an error,
+// since they belong in a hand-written file.
+func moSource(existing []byte, models []*meta.Model, sources []string) ([]byte, error) {
+	pkg, types, err := readMoTypes(existing)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(models, func(i, j int) bool {
+		return meta.CompareVersions(models[i].Version, models[j].Version) < 0
+	})
+	for _, typ := range types {
+		found := false
+		for i := len(models) - 1; i >= 0 && !found; i-- {
+			if class, ok := models[i].Classes[typ.class]; ok {
+				typ.fields = moFields(typ, class)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s (%s) not found in the metadata", typ.name, typ.class)
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by metagen from %s; DO NOT EDIT.\n\n", strings.Join(sources, ", "))
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("// Typed managed objects\n")
+	b.WriteString("// Fields are the commonly used attributes; use goaci.Res for other attributes.\n")
+	b.WriteString("// Read-only attributes are tagged mo:\"ro\" and left out of Body.\n")
+	for _, typ := range types {
+		b.WriteString("\n")
+		if typ.doc != "" {
+			for _, line := range strings.Split(typ.doc, "\n") {
+				fmt.Fprintf(&b, "// %s\n", line)
+			}
+		}
+		fmt.Fprintf(&b, "type %s struct {\n", typ.name)
+		for _, field := range typ.fields {
+			tag := fmt.Sprintf(`json:"%s,omitempty"`, field.prop)
+			if field.readOnly {
+				tag += ` mo:"ro"`
+			}
+			fmt.Fprintf(&b, "%s string `%s`\n", field.name, tag)
+		}
+		b.WriteString("}\n\n")
+		fmt.Fprintf(&b, "// ClassName returns %s.\n", typ.class)
+		fmt.Fprintf(&b, "func (%s) ClassName() string {\nreturn %q\n}\n\n", typ.name, typ.class)
+		b.WriteString("// MarshalJSON encodes the object in the APIC format.\n")
+		fmt.Fprintf(&b, "func (obj %s) MarshalJSON() ([]byte, error) {\n", typ.name)
+		fmt.Fprintf(&b, "type attributes %s\nreturn marshal(obj.ClassName(), attributes(obj))\n}\n\n", typ.name)
+		b.WriteString("// UnmarshalJSON decodes the object from the APIC format.\n")
+		fmt.Fprintf(&b, "func (obj *%s) UnmarshalJSON(data []byte) error {\n", typ.name)
+		fmt.Fprintf(&b, "type attributes %s\nreturn unmarshal(data, obj.ClassName(), (*attributes)(obj))\n}\n", typ.name)
+	}
+	return format.Source(b.Bytes())
+}
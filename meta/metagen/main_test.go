@@ -0,0 +1,133 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/brightpuddle/goaci/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+// testModel parses the metadata fixture.
+func testModel(t *testing.T) *meta.Model {
+	data, err := ioutil.ReadFile("../testdata/aci-meta.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, err := meta.Parse(data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+// TestModelFile tests the modelFile function.
+func TestModelFile(t *testing.T) {
+	assert.Equal(t, "model_5_2_1g.go", modelFile("5.2(1g)"))
+}
+
+// TestModelSource tests the modelSource function.
+func TestModelSource(t *testing.T) {
+	src, err := modelSource(testModel(t), "aci-meta-5.2.json")
+	assert.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "", src, 0)
+	assert.NoError(t, err)
+	code := strings.Join(strings.Fields(string(src)), " ")
+	assert.True(t, strings.HasPrefix(code, "// Code generated by metagen from aci-meta-5.2.json; DO NOT EDIT."))
+	assert.Contains(t, code, `Version: "5.2(1g)",`)
+	assert.Contains(t, code, `RnFormat: "epmactag-{mac}-{[bdName]}",`)
+	assert.Contains(t, code, `Parents: []string{"fvTenant"},`)
+	assert.Contains(t, code, `"arpFlood": {Configurable: true, Values: []string{"no", "yes"}, Default: "no"},`)
+	assert.Contains(t, code, `"pwd": {Configurable: true, Secure: true},`)
+	assert.Contains(t, code, `"prot": {Configurable: true, Values: []string{"unspecified", "icmp", "tcp", "udp"}, Ranges: []Range{{Min: 0, Max: 255}}, Default: "unspecified"},`)
+}
+
+// TestRnSource tests the rnSource function.
+func TestRnSource(t *testing.T) {
+	existing := []byte(`package backup
+
+// Indus RN schema
+
+var rnTemplates = map[string]string{
+	"fvBD":    "BD-{name}",
+	"fvOld":   "old-{name}",
+	"fvRsCtx": "outdated",
+}
+`)
+	src, err := rnSource(existing, []*meta.Model{testModel(t)}, []string{"aci-meta-5.2.1g.json"})
+	assert.NoError(t, err)
+	pkg, table, err := readRnTable(src)
+	assert.NoError(t, err)
+	assert.Equal(t, "backup", pkg)
+	assert.Equal(t, "old-{name}", table["fvOld"])
+	assert.Equal(t, "rsctx", table["fvRsCtx"])
+	assert.Equal(t, "epmactag-{mac}-{[bdName]}", table["fvEpMacTag"])
+	assert.Equal(t, "vlanns-{[name]}-{allocMode}", table["fvnsVlanInstP"])
+	assert.True(t, strings.HasPrefix(string(src), "// Code generated by metagen from aci-meta-5.2.1g.json; DO NOT EDIT."))
+
+	// Missing table
+	_, err = rnSource([]byte("package backup\n"), nil, nil)
+	assert.Error(t, err)
+}
+
+// TestMoSource tests the moSource function.
+func TestMoSource(t *testing.T) {
+	existing := []byte(`package mo
+
+// BD is a bridge domain (fvBD).
+type BD struct {
+	Dn    string ` + "`json:\"dn,omitempty\"`" + `
+	Name  string ` + "`json:\"name,omitempty\"`" + `
+	Scope string ` + "`json:\"scope,omitempty\" mo:\"ro\"`" + `
+	PcTag string ` + "`json:\"pcTag,omitempty\"`" + `
+}
+
+// ClassName returns fvBD.
+func (BD) ClassName() string {
+	return "fvBD"
+}
+`)
+	src, err := moSource(existing, []*meta.Model{testModel(t)}, []string{"aci-meta-5.2.1g.json"})
+	assert.NoError(t, err)
+	pkg, types, err := readMoTypes(src)
+	assert.NoError(t, err)
+	assert.Equal(t, "mo", pkg)
+	assert.True(t, strings.HasPrefix(string(src), "// Code generated by metagen from aci-meta-5.2.1g.json; DO NOT EDIT."))
+	assert.Contains(t, string(src), "// BD is a bridge domain (fvBD).\ntype BD struct")
+
+	// Fields from the metadata, read-only unless configurable
+	bd := types[0]
+	assert.Equal(t, "fvBD", bd.class)
+	assert.Equal(t, moField{name: "Dn", prop: "dn"}, bd.fields[0])
+	assert.Equal(t, moField{name: "Name", prop: "name"}, bd.fields[1])
+	assert.Equal(t, moField{name: "Status", prop: "status"}, bd.fields[len(bd.fields)-1])
+	fields := make(map[string]moField)
+	for _, field := range bd.fields {
+		fields[field.prop] = field
+	}
+	assert.Equal(t, moField{name: "ArpFlood", prop: "arpFlood"}, fields["arpFlood"])
+	assert.Equal(t, moField{name: "PcTag", prop: "pcTag", readOnly: true}, fields["pcTag"])
+	assert.Equal(t, moField{name: "Scope", prop: "scope", readOnly: true}, fields["scope"])
+	assert.NotContains(t, fields, "modTs")
+
+	// Classes missing from the metadata belong in a hand-written file
+	_, err = moSource(append(existing, []byte(`
+// Node is a fabric node (fabricNode).
+type Node struct {
+	Dn string `+"`json:\"dn,omitempty\"`"+`
+}
+
+// ClassName returns fabricNode.
+func (Node) ClassName() string {
+	return "fabricNode"
+}
+`)...), []*meta.Model{testModel(t)}, []string{"aci-meta-5.2.1g.json"})
+	assert.EqualError(t, err, "Node (fabricNode) not found in the metadata")
+
+	// No types
+	_, err = moSource([]byte("package mo\n"), nil, nil)
+	assert.Error(t, err)
+}
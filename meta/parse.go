@@ -0,0 +1,118 @@
+package meta
+
+import (
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// className normalizes a class name from the metadata, e.g. fv:BD to fvBD.
+func className(name string) string {
+	return strings.Replace(name, ":", "", 1)
+}
+
+// bracketedVar matches a bracketed property in an APIC RN format, e.g. [{ip}].
+var bracketedVar = regexp.MustCompile(`\[\{([^{}\[\]]*)\}\]`)
+
+// rnFormat converts an APIC RN format to the RN template form used by goaci, i.e. bracketed
+// properties as {[prop]} rather than [{prop}], e.g. vlanns-[{name}]-{allocMode} to vlanns-{[name]}-{allocMode}.
+func rnFormat(format string) string {
+	return bracketedVar.ReplaceAllString(format, "{[$1]}")
+}
+
+// classNames returns the sorted, normalized keys of a metadata object, e.g. containedBy.
+func classNames(obj gjson.Result) (names []string) {
+	obj.ForEach(func(key, _ gjson.Result) bool {
+		names = append(names, className(key.Str))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// Parse parses the aci-meta.json file served by the APIC at /acimeta/aci-meta.json.
+// The version is read from the file if empty.
+func Parse(data []byte, version string) (*Model, error) {
+	if !gjson.ValidBytes(data) {
+		return nil, errors.New("invalid JSON")
+	}
+	root := gjson.ParseBytes(data)
+	if version == "" {
+		version = root.Get("version").Str
+	}
+	if version == "" {
+		return nil, errors.New("version not found in metadata")
+	}
+	classes := root.Get("classes")
+	if !classes.IsObject() {
+		return nil, errors.New("classes not found in metadata")
+	}
+
+	model := &Model{Version: version, Classes: make(map[string]Class)}
+	classes.ForEach(func(key, value gjson.Result) bool {
+		class := Class{
+			Name:         className(key.Str),
+			RnFormat:     rnFormat(value.Get("rnFormat").Str),
+			Parents:      classNames(value.Get("containedBy")),
+			Children:     classNames(value.Get("contains")),
+			Configurable: value.Get("isConfigurable").Bool(),
+			Properties:   make(map[string]Property),
+		}
+		for _, name := range value.Get("identifiedBy").Array() {
+			class.Naming = append(class.Naming, name.Str)
+		}
+		value.Get("properties").ForEach(func(name, prop gjson.Result) bool {
+			p := Property{
+				Configurable: prop.Get("isConfigurable").Bool(),
+				Default:      prop.Get("default").Str,
+				Secure:       prop.Get("isSecure").Bool(),
+			}
+			seen := make(map[string]bool)
+			for _, v := range prop.Get("validValues").Array() {
+				// defaultValue entries repeat one of the values
+				value := v.Get("value").Str
+				if v.Get("localName").Str == "defaultValue" {
+					if !prop.Get("default").Exists() {
+						p.Default = value
+					}
+				} else if !seen[value] {
+					seen[value] = true
+					p.Values = append(p.Values, value)
+				}
+			}
+			for _, v := range prop.Get("validators").Array() {
+				min, errMin := strconv.ParseInt(v.Get("min").String(), 0, 64)
+				max, errMax := strconv.ParseInt(v.Get("max").String(), 0, 64)
+				if errMin == nil && errMax == nil {
+					p.Ranges = append(p.Ranges, Range{Min: min, Max: max})
+				}
+			}
+			class.Properties[name.Str] = p
+			return true
+		})
+		model.Classes[class.Name] = class
+		return true
+	})
+	return model, nil
+}
+
+// LoadFile parses and registers an aci-meta.json file, e.g.
+//  meta.LoadFile("aci-meta-5.2.json", "5.2(1g)")
+// Use this instead of generated models to load metadata at runtime.
+func LoadFile(path, version string) (*Model, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	model, err := Parse(data, version)
+	if err != nil {
+		return nil, err
+	}
+	Register(model)
+	return model, nil
+}
@@ -0,0 +1,60 @@
+package meta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse tests the Parse function.
+func TestParse(t *testing.T) {
+	model, err := LoadFile("testdata/aci-meta.json", "")
+	assert.NoError(t, err)
+	defer Unregister(model.Version)
+	assert.Equal(t, "5.2(1g)", model.Version)
+
+	bd := model.Classes["fvBD"]
+	assert.Equal(t, "BD-{name}", bd.RnFormat)
+	assert.Equal(t, []string{"name"}, bd.Naming)
+	assert.Equal(t, []string{"fvTenant"}, bd.Parents)
+	assert.Equal(t, []string{"fvRsCtx", "fvSubnet"}, bd.Children)
+	assert.True(t, bd.Configurable)
+	assert.Equal(t, []string{"no", "yes"}, bd.Properties["arpFlood"].Values)
+	assert.Equal(t, "no", bd.Properties["arpFlood"].Default)
+	assert.Equal(t, "", bd.Properties["descr"].Default)
+	assert.Contains(t, bd.ReadOnly(), "pcTag")
+	assert.NotContains(t, bd.ReadOnly(), "name")
+	assert.Empty(t, bd.Secure())
+	assert.Equal(t, []string{"pwd"}, model.Classes["aaaUser"].Secure())
+	port := model.Classes["vzEntry"].Properties["dFromPort"]
+	assert.Equal(t, []Range{{Min: 0, Max: 65535}}, port.Ranges)
+	assert.Contains(t, port.Values, "http")
+	assert.Equal(t, "unspecified", port.Default)
+
+	tag := model.Classes["fvEpMacTag"]
+	assert.Equal(t, []string{"mac", "bdName"}, tag.Naming)
+
+	// Registered
+	class, ok := Lookup("fvEpMacTag")
+	assert.True(t, ok)
+	assert.Equal(t, "epmactag-{mac}-{[bdName]}", class.RnFormat)
+
+	// Bracketed properties followed by another property
+	class, _ = Lookup("fvnsVlanInstP")
+	assert.Equal(t, "vlanns-{[name]}-{allocMode}", class.RnFormat)
+
+	// Version override
+	model, err = Parse([]byte(`{"version":"5.2(1g)","classes":{}}`), "6.0(1a)")
+	assert.NoError(t, err)
+	assert.Equal(t, "6.0(1a)", model.Version)
+
+	// Errors
+	_, err = Parse([]byte(`{"classes":{}}`), "")
+	assert.Error(t, err)
+	_, err = Parse([]byte(`{"version":"5.2(1g)"}`), "")
+	assert.Error(t, err)
+	_, err = Parse([]byte(`{`), "5.2(1g)")
+	assert.Error(t, err)
+	_, err = LoadFile("testdata/nonexistent.json", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+// Package mo provides typed structs for common managed objects.
+// The structs marshal to and from the APIC JSON format, i.e. {"fvTenant": {"attributes": {...}}}, e.g.
+//  res, _ := client.GetClass("fvTenant")
+//  var tenants []mo.Tenant
+//  mo.Decode(res, &tenants)
+//
+//  body, _ := mo.Body(mo.Tenant{Name: "new"}, mo.BD{Name: "web"})
+//  client.Post("/api/mo/uni/tn-new", body.Str)
+// Attribute values are strings, as returned by the APIC.
+package mo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/tidwall/sjson"
+)
+
+// Object is a typed managed object.
+type Object interface {
+	// ClassName returns the APIC class name, e.g. fvTenant.
+	ClassName() string
+}
+
+// marshal wraps attributes in the APIC object format.
+func marshal(class string, attributes interface{}) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		class: map[string]interface{}{"attributes": attributes},
+	})
+}
+
+// unmarshal reads attributes from the APIC object format.
+func unmarshal(data []byte, class string, attributes interface{}) error {
+	var obj map[string]struct {
+		Attributes json.RawMessage `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	body, ok := obj[class]
+	if !ok || len(obj) != 1 {
+		return fmt.Errorf("expected a %s object", class)
+	}
+	if body.Attributes == nil {
+		return nil
+	}
+	return json.Unmarshal(body.Attributes, attributes)
+}
+
+// Decode decodes an API result into a typed struct or slice of structs, e.g.
+//  res, _ := client.GetDn("uni/tn-mytenant")
+//  var tenant mo.Tenant
+//  mo.Decode(res, &tenant)
+// Full responses are unwrapped from imdata.
+func Decode(res goaci.Res, v interface{}) error {
+	if imdata := res.Get("imdata"); imdata.Exists() {
+		res = imdata
+	}
+	if !res.Exists() {
+		return fmt.Errorf("no result to decode")
+	}
+	return json.Unmarshal([]byte(res.Raw), v)
+}
+
+// configurable encodes an object in the APIC format without its read-only attributes,
+// i.e. the fields tagged mo:"ro".
+func configurable(obj Object) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	typ := reflect.Indirect(reflect.ValueOf(obj)).Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Tag.Get("mo") != "ro" {
+			continue
+		}
+		prop := strings.Split(field.Tag.Get("json"), ",")[0]
+		data, err = sjson.DeleteBytes(data, obj.ClassName()+".attributes."+prop)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// Body builds a POST body from an object and its children.
+// Read-only attributes, e.g. the pcTag of a decoded EPG, are left out.
+// Use the Body methods to further modify the result, e.g.
+//  body, _ := mo.Body(mo.BD{Name: "web"}, mo.Subnet{Ip: "10.0.0.1/24"})
+//  body = body.Set("fvBD.attributes.descr", "Web servers")
+func Body(obj Object, children ...Object) (goaci.Body, error) {
+	data, err := configurable(obj)
+	if err != nil {
+		return goaci.Body{}, err
+	}
+	body := goaci.Body{Str: string(data)}
+	for _, child := range children {
+		data, err := configurable(child)
+		if err != nil {
+			return goaci.Body{}, err
+		}
+		body = body.SetRaw(obj.ClassName()+".children.-1", string(data))
+	}
+	return body, nil
+}
@@ -0,0 +1,81 @@
+package mo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+// TestMarshal tests encoding objects in the APIC format.
+func TestMarshal(t *testing.T) {
+	data, err := json.Marshal(Tenant{Name: "a", Descr: "tenant"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"fvTenant":{"attributes":{"name":"a","descr":"tenant"}}}`, string(data))
+
+	data, _ = json.Marshal(&EPG{Name: "web"})
+	assert.Equal(t, `{"fvAEPg":{"attributes":{"name":"web"}}}`, string(data))
+}
+
+// TestUnmarshal tests decoding objects from the APIC format.
+func TestUnmarshal(t *testing.T) {
+	var bd BD
+	assert.NoError(t, json.Unmarshal([]byte(`{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-web","name":"web","arpFlood":"yes","other":"x"}}}`), &bd))
+	assert.Equal(t, BD{Dn: "uni/tn-a/BD-web", Name: "web", ArpFlood: "yes"}, bd)
+
+	// Wrong class
+	assert.Error(t, json.Unmarshal([]byte(`{"fvCtx":{"attributes":{"name":"vrf"}}}`), &bd))
+
+	// No attributes
+	var tenant Tenant
+	assert.NoError(t, json.Unmarshal([]byte(`{"fvTenant":{}}`), &tenant))
+}
+
+// TestDecode tests the Decode function.
+func TestDecode(t *testing.T) {
+	// Class query result
+	res := gjson.Parse(`[
+		{"fabricNode":{"attributes":{"id":"101","role":"leaf"}}},
+		{"fabricNode":{"attributes":{"id":"201","role":"spine"}}}
+	]`)
+	var nodes []FabricNode
+	assert.NoError(t, Decode(res, &nodes))
+	assert.Equal(t, []FabricNode{{Id: "101", Role: "leaf"}, {Id: "201", Role: "spine"}}, nodes)
+
+	// Full response
+	res = goaci.Body{}.Set("imdata.0.faultInst.attributes.code", "F0532").Res()
+	var faults []Fault
+	assert.NoError(t, Decode(res, &faults))
+	assert.Equal(t, "F0532", faults[0].Code)
+
+	// Single object
+	var tenant Tenant
+	assert.NoError(t, Decode(gjson.Parse(`{"fvTenant":{"attributes":{"name":"a"}}}`), &tenant))
+	assert.Equal(t, "a", tenant.Name)
+
+	// Empty result
+	assert.Error(t, Decode(gjson.Result{}, &tenant))
+}
+
+// TestBody tests the Body function.
+func TestBody(t *testing.T) {
+	body, err := Body(BD{Name: "web"}, Subnet{Ip: "10.0.0.1/24", Scope: "public"}, &Subnet{Ip: "10.0.1.1/24"})
+	assert.NoError(t, err)
+	res := body.Res()
+	assert.Equal(t, "web", res.Get("fvBD.attributes.name").Str)
+	assert.Equal(t, "public", res.Get("fvBD.children.0.fvSubnet.attributes.scope").Str)
+	assert.Equal(t, "10.0.1.1/24", res.Get("fvBD.children.1.fvSubnet.attributes.ip").Str)
+
+	body = body.Set("fvBD.attributes.descr", "Web servers")
+	assert.Equal(t, "Web servers", body.Res().Get("fvBD.attributes.descr").Str)
+
+	// Read-only attributes of a decoded object
+	var bd BD
+	assert.NoError(t, Decode(gjson.Parse(`{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-web","name":"web","pcTag":"49153","seg":"16777209","scope":"2523136"}}}`), &bd))
+	assert.Equal(t, "49153", bd.PcTag)
+	body, err = Body(&bd)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-web","name":"web"}}}`, body.Str)
+}
@@ -0,0 +1,174 @@
+package mo
+
+// Typed managed objects for classes without metadata in the repository, maintained by hand.
+// Move a type to classes.go to generate its fields once its class is in the metadata.
+
+// Contract is a contract (vzBrCP).
+type Contract struct {
+	Dn         string `json:"dn,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Descr      string `json:"descr,omitempty"`
+	NameAlias  string `json:"nameAlias,omitempty"`
+	Intent     string `json:"intent,omitempty"`
+	Prio       string `json:"prio,omitempty"`
+	Scope      string `json:"scope,omitempty"`
+	TargetDscp string `json:"targetDscp,omitempty"`
+	Annotation string `json:"annotation,omitempty"`
+	Status     string `json:"status,omitempty"`
+}
+
+// ClassName returns vzBrCP.
+func (Contract) ClassName() string {
+	return "vzBrCP"
+}
+
+// MarshalJSON encodes the object in the APIC format.
+func (obj Contract) MarshalJSON() ([]byte, error) {
+	type attributes Contract
+	return marshal(obj.ClassName(), attributes(obj))
+}
+
+// UnmarshalJSON decodes the object from the APIC format.
+func (obj *Contract) UnmarshalJSON(data []byte) error {
+	type attributes Contract
+	return unmarshal(data, obj.ClassName(), (*attributes)(obj))
+}
+
+// L3Out is an L3Out (l3extOut).
+type L3Out struct {
+	Dn            string `json:"dn,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Descr         string `json:"descr,omitempty"`
+	NameAlias     string `json:"nameAlias,omitempty"`
+	EnforceRtctrl string `json:"enforceRtctrl,omitempty"`
+	TargetDscp    string `json:"targetDscp,omitempty"`
+	Annotation    string `json:"annotation,omitempty"`
+	Status        string `json:"status,omitempty"`
+}
+
+// ClassName returns l3extOut.
+func (L3Out) ClassName() string {
+	return "l3extOut"
+}
+
+// MarshalJSON encodes the object in the APIC format.
+func (obj L3Out) MarshalJSON() ([]byte, error) {
+	type attributes L3Out
+	return marshal(obj.ClassName(), attributes(obj))
+}
+
+// UnmarshalJSON decodes the object from the APIC format.
+func (obj *L3Out) UnmarshalJSON(data []byte) error {
+	type attributes L3Out
+	return unmarshal(data, obj.ClassName(), (*attributes)(obj))
+}
+
+// FabricNode is a fabric node, i.e. a leaf, spine or controller (fabricNode).
+type FabricNode struct {
+	Dn             string `json:"dn,omitempty"`
+	Id             string `json:"id,omitempty" mo:"ro"`
+	Name           string `json:"name,omitempty" mo:"ro"`
+	NameAlias      string `json:"nameAlias,omitempty" mo:"ro"`
+	AdSt           string `json:"adSt,omitempty" mo:"ro"`
+	Address        string `json:"address,omitempty" mo:"ro"`
+	FabricSt       string `json:"fabricSt,omitempty" mo:"ro"`
+	LastStateModTs string `json:"lastStateModTs,omitempty" mo:"ro"`
+	Model          string `json:"model,omitempty" mo:"ro"`
+	NodeType       string `json:"nodeType,omitempty" mo:"ro"`
+	Role           string `json:"role,omitempty" mo:"ro"`
+	Serial         string `json:"serial,omitempty" mo:"ro"`
+	Vendor         string `json:"vendor,omitempty" mo:"ro"`
+	Version        string `json:"version,omitempty" mo:"ro"`
+}
+
+// ClassName returns fabricNode.
+func (FabricNode) ClassName() string {
+	return "fabricNode"
+}
+
+// MarshalJSON encodes the object in the APIC format.
+func (obj FabricNode) MarshalJSON() ([]byte, error) {
+	type attributes FabricNode
+	return marshal(obj.ClassName(), attributes(obj))
+}
+
+// UnmarshalJSON decodes the object from the APIC format.
+func (obj *FabricNode) UnmarshalJSON(data []byte) error {
+	type attributes FabricNode
+	return unmarshal(data, obj.ClassName(), (*attributes)(obj))
+}
+
+// TopSystem is the system information of a node (topSystem).
+type TopSystem struct {
+	Dn           string `json:"dn,omitempty"`
+	Id           string `json:"id,omitempty" mo:"ro"`
+	Name         string `json:"name,omitempty" mo:"ro"`
+	Address      string `json:"address,omitempty" mo:"ro"`
+	FabricId     string `json:"fabricId,omitempty" mo:"ro"`
+	FabricMac    string `json:"fabricMAC,omitempty" mo:"ro"`
+	InbMgmtAddr  string `json:"inbMgmtAddr,omitempty" mo:"ro"`
+	OobMgmtAddr  string `json:"oobMgmtAddr,omitempty" mo:"ro"`
+	PodId        string `json:"podId,omitempty" mo:"ro"`
+	Role         string `json:"role,omitempty" mo:"ro"`
+	Serial       string `json:"serial,omitempty" mo:"ro"`
+	State        string `json:"state,omitempty" mo:"ro"`
+	SystemUpTime string `json:"systemUpTime,omitempty" mo:"ro"`
+	Version      string `json:"version,omitempty" mo:"ro"`
+}
+
+// ClassName returns topSystem.
+func (TopSystem) ClassName() string {
+	return "topSystem"
+}
+
+// MarshalJSON encodes the object in the APIC format.
+func (obj TopSystem) MarshalJSON() ([]byte, error) {
+	type attributes TopSystem
+	return marshal(obj.ClassName(), attributes(obj))
+}
+
+// UnmarshalJSON decodes the object from the APIC format.
+func (obj *TopSystem) UnmarshalJSON(data []byte) error {
+	type attributes TopSystem
+	return unmarshal(data, obj.ClassName(), (*attributes)(obj))
+}
+
+// Fault is a fault instance (faultInst).
+type Fault struct {
+	Dn              string `json:"dn,omitempty"`
+	Code            string `json:"code,omitempty" mo:"ro"`
+	Ack             string `json:"ack,omitempty" mo:"ro"`
+	Cause           string `json:"cause,omitempty" mo:"ro"`
+	ChangeSet       string `json:"changeSet,omitempty" mo:"ro"`
+	Created         string `json:"created,omitempty" mo:"ro"`
+	Delegated       string `json:"delegated,omitempty" mo:"ro"`
+	Descr           string `json:"descr,omitempty" mo:"ro"`
+	Domain          string `json:"domain,omitempty" mo:"ro"`
+	HighestSeverity string `json:"highestSeverity,omitempty" mo:"ro"`
+	LastTransition  string `json:"lastTransition,omitempty" mo:"ro"`
+	Lc              string `json:"lc,omitempty" mo:"ro"`
+	Occur           string `json:"occur,omitempty" mo:"ro"`
+	OrigSeverity    string `json:"origSeverity,omitempty" mo:"ro"`
+	PrevSeverity    string `json:"prevSeverity,omitempty" mo:"ro"`
+	Rule            string `json:"rule,omitempty" mo:"ro"`
+	Severity        string `json:"severity,omitempty" mo:"ro"`
+	Subject         string `json:"subject,omitempty" mo:"ro"`
+	Type            string `json:"type,omitempty" mo:"ro"`
+}
+
+// ClassName returns faultInst.
+func (Fault) ClassName() string {
+	return "faultInst"
+}
+
+// MarshalJSON encodes the object in the APIC format.
+func (obj Fault) MarshalJSON() ([]byte, error) {
+	type attributes Fault
+	return marshal(obj.ClassName(), attributes(obj))
+}
+
+// UnmarshalJSON decodes the object from the APIC format.
+func (obj *Fault) UnmarshalJSON(data []byte) error {
+	type attributes Fault
+	return unmarshal(data, obj.ClassName(), (*attributes)(obj))
+}
@@ -0,0 +1,241 @@
+package profiles
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Provider provides the credentials of a profile.
+type Provider interface {
+	// Credentials returns the username and password for an APIC URL of a profile.
+	// An empty username keeps the profile username.
+	Credentials(p Profile, url string) (usr, pwd string, err error)
+}
+
+// ProviderFunc adapts a function to a Provider.
+type ProviderFunc func(p Profile, url string) (string, string, error)
+
+// Credentials calls the function.
+func (fn ProviderFunc) Credentials(p Profile, url string) (string, string, error) {
+	return fn(p, url)
+}
+
+var (
+	mu        sync.RWMutex
+	providers = make(map[string]Provider)
+)
+
+// RegisterProvider registers a custom credential provider, e.g. for a secrets manager.
+// Profiles select it by name:
+//  credentials:
+//    provider: vault
+func RegisterProvider(name string, provider Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[name] = provider
+}
+
+// Credentials selects the credential provider of a profile. Set one of the fields.
+type Credentials struct {
+	// Env is an environment variable with the password.
+	Env string `yaml:"env"`
+	// File is a file with the password, only accessible by the user.
+	File string `yaml:"file"`
+	// Netrc is a netrc file with the login and password of the APIC host, e.g. ~/.netrc.
+	Netrc string `yaml:"netrc"`
+	// Command is a shell command printing the password, e.g. pass show aci/lab.
+	// GOACI_PROFILE, GOACI_URL and GOACI_USERNAME are set for the command.
+	Command string `yaml:"command"`
+	// Provider is the name of a provider registered with RegisterProvider.
+	Provider string `yaml:"provider"`
+}
+
+// provider returns the selected provider.
+func (c Credentials) provider() (Provider, error) {
+	var selected []Provider
+	if c.Env != "" {
+		selected = append(selected, Env(c.Env))
+	}
+	if c.File != "" {
+		selected = append(selected, File(c.File))
+	}
+	if c.Netrc != "" {
+		selected = append(selected, Netrc(c.Netrc))
+	}
+	if c.Command != "" {
+		selected = append(selected, Command(c.Command))
+	}
+	if c.Provider != "" {
+		mu.RLock()
+		provider, ok := providers[c.Provider]
+		mu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("credential provider %s not registered", c.Provider)
+		}
+		selected = append(selected, provider)
+	}
+	switch len(selected) {
+	case 0:
+		return nil, errors.New("no credentials configured")
+	case 1:
+		return selected[0], nil
+	}
+	return nil, errors.New("more than one credential provider configured")
+}
+
+// Env provides the password from an environment variable.
+type Env string
+
+// Credentials returns the password from the environment variable.
+func (env Env) Credentials(p Profile, url string) (string, string, error) {
+	pwd, ok := os.LookupEnv(string(env))
+	if !ok {
+		return "", "", fmt.Errorf("environment variable %s not set", env)
+	}
+	return "", pwd, nil
+}
+
+// File provides the password from a file only accessible by the user.
+// Surrounding whitespace is removed.
+type File string
+
+// Credentials returns the password from the file.
+func (file File) Credentials(p Profile, url string) (string, string, error) {
+	path := expand(string(file))
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return "", "", fmt.Errorf("%s is accessible by other users", path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	return "", strings.TrimSpace(string(data)), nil
+}
+
+// Netrc provides the login and password of the APIC host from a netrc file.
+// The default entry is used for hosts without a machine entry.
+type Netrc string
+
+// host returns the host name of an APIC URL, e.g. 10.0.0.1 for https://10.0.0.1:443.
+func host(apic string) string {
+	if !strings.Contains(apic, "://") {
+		apic = "https://" + apic
+	}
+	u, err := url.Parse(apic)
+	if err != nil {
+		return apic
+	}
+	return u.Hostname()
+}
+
+// Credentials returns the login and password of the APIC host.
+func (netrc Netrc) Credentials(p Profile, url string) (string, string, error) {
+	data, err := ioutil.ReadFile(expand(string(netrc)))
+	if err != nil {
+		return "", "", err
+	}
+	// Tokens, skipping macro definitions, which end at a blank line
+	var tokens []string
+	inMacro := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		fields := strings.Fields(line)
+		for i, field := range fields {
+			if field == "macdef" {
+				inMacro = true
+				fields = fields[:i]
+				break
+			}
+		}
+		tokens = append(tokens, fields...)
+	}
+
+	type entry struct{ login, password string }
+	var match, fallback *entry
+	var current *entry
+	target := host(url)
+	for i := 0; i < len(tokens); i++ {
+		next := ""
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+		switch tokens[i] {
+		case "machine":
+			current = &entry{}
+			if next == target && match == nil {
+				match = current
+			}
+			i++
+		case "default":
+			current = &entry{}
+			if fallback == nil {
+				fallback = current
+			}
+		case "login":
+			if current != nil {
+				current.login = next
+			}
+			i++
+		case "password":
+			if current != nil {
+				current.password = next
+			}
+			i++
+		case "account":
+			i++
+		}
+	}
+	if match == nil {
+		match = fallback
+	}
+	if match == nil {
+		return "", "", fmt.Errorf("no netrc entry for %s", target)
+	}
+	return match.login, match.password, nil
+}
+
+// Command provides the password printed by a shell command, e.g. a password manager CLI.
+// The first line of the output is used.
+type Command string
+
+// Credentials runs the command and returns the first line of its output.
+func (command Command) Credentials(p Profile, url string) (string, string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", string(command))
+	} else {
+		cmd = exec.Command("sh", "-c", string(command))
+	}
+	cmd.Env = append(os.Environ(),
+		"GOACI_PROFILE="+p.Name,
+		"GOACI_URL="+url,
+		"GOACI_USERNAME="+p.Username,
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", "", fmt.Errorf("credential command: %v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", "", fmt.Errorf("credential command: %v", err)
+	}
+	line := strings.SplitN(string(out), "\n", 2)[0]
+	return "", strings.TrimRight(line, "\r"), nil
+}
@@ -0,0 +1,114 @@
+package profiles
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testNetrc = `machine 10.0.0.1 login admin password secret
+machine apic.example.com
+  login automation
+  password other
+macdef init
+  machine 10.0.0.1 login wrong password wrong
+
+default login guest password guest
+`
+
+// TestCredentialsProvider tests selecting credential providers.
+func TestCredentialsProvider(t *testing.T) {
+	provider, err := Credentials{Env: "A"}.provider()
+	assert.NoError(t, err)
+	assert.Equal(t, Env("A"), provider)
+
+	_, err = Credentials{}.provider()
+	assert.EqualError(t, err, "no credentials configured")
+	_, err = Credentials{Env: "A", File: "b"}.provider()
+	assert.Error(t, err)
+
+	// Custom providers
+	_, err = Credentials{Provider: "test"}.provider()
+	assert.EqualError(t, err, "credential provider test not registered")
+	RegisterProvider("test", ProviderFunc(func(p Profile, url string) (string, string, error) {
+		return "", p.Name + "-" + url, nil
+	}))
+	provider, err = Credentials{Provider: "test"}.provider()
+	assert.NoError(t, err)
+	_, pwd, _ := provider.Credentials(Profile{Name: "lab"}, "10.0.0.1")
+	assert.Equal(t, "lab-10.0.0.1", pwd)
+}
+
+// TestEnv tests the environment variable provider.
+func TestEnv(t *testing.T) {
+	os.Setenv("GOACI_TEST_PASSWORD", "secret")
+	defer os.Unsetenv("GOACI_TEST_PASSWORD")
+	_, pwd, err := Env("GOACI_TEST_PASSWORD").Credentials(Profile{}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", pwd)
+	_, _, err = Env("GOACI_TEST_MISSING").Credentials(Profile{}, "")
+	assert.Error(t, err)
+}
+
+// TestFile tests the password file provider.
+func TestFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "goaci")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "password")
+	ioutil.WriteFile(path, []byte("secret\n"), 0600)
+
+	_, pwd, err := File(path).Credentials(Profile{}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", pwd)
+
+	if runtime.GOOS != "windows" {
+		os.Chmod(path, 0644)
+		_, _, err = File(path).Credentials(Profile{}, "")
+		assert.Error(t, err)
+	}
+	_, _, err = File(filepath.Join(dir, "missing")).Credentials(Profile{}, "")
+	assert.Error(t, err)
+}
+
+// TestNetrc tests the netrc provider.
+func TestNetrc(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "goaci")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "netrc")
+	ioutil.WriteFile(path, []byte(testNetrc), 0600)
+	netrc := Netrc(path)
+
+	for url, expected := range map[string][2]string{
+		"10.0.0.1":                      {"admin", "secret"},
+		"https://apic.example.com:8443": {"automation", "other"},
+		"10.0.0.2":                      {"guest", "guest"},
+	} {
+		usr, pwd, err := netrc.Credentials(Profile{}, url)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, [2]string{usr, pwd}, url)
+	}
+
+	ioutil.WriteFile(path, []byte("machine 10.0.0.1 login admin password secret"), 0600)
+	_, _, err := netrc.Credentials(Profile{}, "10.0.0.2")
+	assert.EqualError(t, err, "no netrc entry for 10.0.0.2")
+}
+
+// TestCommand tests the external command provider.
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	p := Profile{Name: "lab", Username: "admin"}
+	_, pwd, err := Command(`echo "$GOACI_PROFILE-$GOACI_USERNAME-$GOACI_URL"; echo second`).Credentials(p, "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "lab-admin-10.0.0.1", pwd)
+
+	_, _, err = Command("echo locked >&2; exit 1").Credentials(p, "10.0.0.1")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "locked")
+	}
+}
@@ -0,0 +1,309 @@
+// Package profiles creates goaci clients from named fabric profiles in a configuration file.
+//
+// The configuration file is YAML (or JSON), e.g.
+//  defaults:
+//    username: admin
+//    credentials:
+//      netrc: ~/.netrc
+//  profiles:
+//    lab:
+//      urls: [10.0.0.1, 10.0.0.2]
+//      domain: tacacs
+//      timeout: 120
+//      credentials:
+//        env: LAB_PASSWORD
+//    prod:
+//      urls: [apic1.example.com]
+//      auth: certificate
+//      certificate:
+//        name: automation
+//        key: ~/.goaci/automation.key
+//      tls:
+//        ca: ~/.goaci/ca.pem
+// Profile settings override the defaults, field by field for the tls and certificate settings.
+package profiles
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brightpuddle/goaci"
+	"gopkg.in/yaml.v3"
+)
+
+// Authentication methods.
+const (
+	// AuthPassword logs in with a username and password from the credential provider.
+	AuthPassword = "password"
+	// AuthCertificate signs requests with a user certificate key. See goaci.CertAuth.
+	AuthCertificate = "certificate"
+)
+
+// TLS are the TLS options of a profile.
+type TLS struct {
+	// Verify enables certificate verification. APIC certificates are self-signed by default,
+	// so verification is disabled unless Verify is set or a CA file is given.
+	// Verify is a pointer so that a profile can disable verification enabled in the defaults.
+	Verify *bool `yaml:"verify"`
+	// CA is a PEM file with the CA certificates to verify the APIC certificate.
+	CA string `yaml:"ca"`
+	// ServerName overrides the name used to verify the APIC certificate.
+	ServerName string `yaml:"serverName"`
+}
+
+// Certificate is the user certificate for certificate authentication.
+type Certificate struct {
+	// Name is the name of the aaaUserCert object of the user.
+	Name string `yaml:"name"`
+	// Key is the PEM file with the private key.
+	Key string `yaml:"key"`
+}
+
+// Profile is a named fabric profile.
+type Profile struct {
+	// Name is the profile name.
+	Name string `yaml:"-"`
+	// URLs are the APICs of the fabric. Login tries each in order.
+	URLs []string `yaml:"urls"`
+	// Username is the APIC username.
+	Username string `yaml:"username"`
+	// Domain is the login domain, e.g. for TACACS or RADIUS users.
+	Domain string `yaml:"domain"`
+	// Auth is the authentication method, password (default) or certificate.
+	Auth        string      `yaml:"auth"`
+	Certificate Certificate `yaml:"certificate"`
+	TLS         TLS         `yaml:"tls"`
+	// Timeout is the request timeout in seconds.
+	Timeout int `yaml:"timeout"`
+	// Credentials selects the credential provider for the password.
+	Credentials Credentials `yaml:"credentials"`
+}
+
+// Config is a configuration file with fabric profiles.
+type Config struct {
+	// Defaults apply to all profiles.
+	Defaults Profile            `yaml:"defaults"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// DefaultPath returns the configuration file path, i.e. $GOACI_CONFIG or ~/.goaci/config.yaml.
+func DefaultPath() (string, error) {
+	if path := os.Getenv("GOACI_CONFIG"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".goaci", "config.yaml"), nil
+}
+
+// expand expands a leading ~ to the home directory.
+func expand(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// Parse parses a configuration in YAML or JSON format.
+func Parse(data []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Load reads a configuration file. Pass an empty path for the default path (see DefaultPath).
+func Load(path string) (Config, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return Config{}, err
+		}
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// Names returns the profile names, sorted.
+func (cfg Config) Names() []string {
+	var names []string
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns a profile by name, with the defaults applied.
+func (cfg Config) Profile(name string) (Profile, error) {
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %s not found", name)
+	}
+	d := cfg.Defaults
+	p.Name = name
+	if len(p.URLs) == 0 {
+		p.URLs = d.URLs
+	}
+	if p.Username == "" {
+		p.Username = d.Username
+	}
+	if p.Domain == "" {
+		p.Domain = d.Domain
+	}
+	if p.Auth == "" {
+		p.Auth = d.Auth
+	}
+	if p.Auth == "" {
+		p.Auth = AuthPassword
+	}
+	if p.Certificate.Name == "" {
+		p.Certificate.Name = d.Certificate.Name
+	}
+	if p.Certificate.Key == "" {
+		p.Certificate.Key = d.Certificate.Key
+	}
+	if p.TLS.Verify == nil {
+		p.TLS.Verify = d.TLS.Verify
+	}
+	if p.TLS.CA == "" {
+		p.TLS.CA = d.TLS.CA
+	}
+	if p.TLS.ServerName == "" {
+		p.TLS.ServerName = d.TLS.ServerName
+	}
+	if p.Timeout == 0 {
+		p.Timeout = d.Timeout
+	}
+	if p.Credentials == (Credentials{}) {
+		p.Credentials = d.Credentials
+	}
+	if len(p.URLs) == 0 {
+		return Profile{}, fmt.Errorf("profile %s has no urls", name)
+	}
+	return p, nil
+}
+
+// login returns the APIC login name, including the login domain, e.g. apic#tacacs\admin.
+func (p Profile) login() string {
+	if p.Domain == "" {
+		return p.Username
+	}
+	return "apic#" + p.Domain + `\` + p.Username
+}
+
+// tlsConfig returns the TLS configuration of the profile.
+func (p Profile) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: (p.TLS.Verify == nil || !*p.TLS.Verify) && p.TLS.CA == "",
+		ServerName:         p.TLS.ServerName,
+	}
+	if p.TLS.CA != "" {
+		data, err := ioutil.ReadFile(expand(p.TLS.CA))
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%s: no certificates", p.TLS.CA)
+		}
+	}
+	return cfg, nil
+}
+
+// NewClient creates a client for a URL of the profile, without logging in.
+// Modifiers are applied after the profile settings.
+func (p Profile) NewClient(url string, mods ...func(*goaci.Client)) (goaci.Client, error) {
+	tlsConfig, err := p.tlsConfig()
+	if err != nil {
+		return goaci.Client{}, err
+	}
+	settings := []func(*goaci.Client){func(client *goaci.Client) {
+		client.HttpClient.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+		if p.Timeout > 0 {
+			client.HttpClient.Timeout = time.Duration(p.Timeout) * time.Second
+		}
+	}}
+
+	pwd := ""
+	switch p.Auth {
+	case AuthCertificate:
+		if p.Certificate.Name == "" || p.Certificate.Key == "" {
+			return goaci.Client{}, fmt.Errorf("profile %s: certificate name and key required", p.Name)
+		}
+		key, err := goaci.ReadPrivateKey(expand(p.Certificate.Key))
+		if err != nil {
+			return goaci.Client{}, err
+		}
+		settings = append(settings, goaci.CertAuth(p.Certificate.Name, key))
+	case AuthPassword:
+		provider, err := p.Credentials.provider()
+		if err != nil {
+			return goaci.Client{}, fmt.Errorf("profile %s: %v", p.Name, err)
+		}
+		usr, password, err := provider.Credentials(p, url)
+		if err != nil {
+			return goaci.Client{}, fmt.Errorf("profile %s: %v", p.Name, err)
+		}
+		if usr != "" && p.Username == "" {
+			p.Username = usr
+		}
+		pwd = password
+	default:
+		return goaci.Client{}, fmt.Errorf("profile %s: unknown auth method %s", p.Name, p.Auth)
+	}
+	if p.Username == "" {
+		return goaci.Client{}, fmt.Errorf("profile %s has no username", p.Name)
+	}
+	return goaci.NewClient(url, p.login(), pwd, append(settings, mods...)...)
+}
+
+// Client returns a logged in client for a profile, e.g.
+//  cfg, _ := profiles.Load("")
+//  client, err := cfg.Client("lab")
+// Login tries the URLs of the profile in order, so that the first available APIC is used.
+// A session restored with goaci.CacheSession is used without logging in; the client logs in
+// again if the APIC rejects it.
+func (cfg Config) Client(name string, mods ...func(*goaci.Client)) (goaci.Client, error) {
+	p, err := cfg.Profile(name)
+	if err != nil {
+		return goaci.Client{}, err
+	}
+	var errs []string
+	for _, url := range p.URLs {
+		client, err := p.NewClient(url, mods...)
+		if err != nil {
+			return goaci.Client{}, err
+		}
+		if client.Token != "" {
+			return client, nil
+		}
+		if err := client.Login(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", url, err))
+			continue
+		}
+		return client, nil
+	}
+	return goaci.Client{}, errors.New("login failed for profile " + name + "\n" + strings.Join(errs, "\n"))
+}
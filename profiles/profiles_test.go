@@ -0,0 +1,238 @@
+package profiles
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const testConfig = `
+defaults:
+  username: admin
+  timeout: 30
+  credentials:
+    env: GOACI_TEST_PASSWORD
+profiles:
+  lab:
+    urls: [10.0.0.1, 10.0.0.2]
+    domain: tacacs
+    timeout: 120
+  prod:
+    urls: [apic.example.com]
+    username: automation
+    auth: certificate
+    certificate:
+      name: automation
+      key: KEY
+    tls:
+      verify: true
+  empty: {}
+`
+
+// intercept intercepts client requests with gock.
+func intercept(client *goaci.Client) {
+	gock.InterceptClient(client.HttpClient)
+}
+
+// TestLoad tests loading configuration files and profiles.
+func TestLoad(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "goaci")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	ioutil.WriteFile(path, []byte(testConfig), 0600)
+
+	os.Setenv("GOACI_CONFIG", path)
+	defer os.Unsetenv("GOACI_CONFIG")
+	cfg, err := Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"empty", "lab", "prod"}, cfg.Names())
+
+	lab, err := cfg.Profile("lab")
+	assert.NoError(t, err)
+	assert.Equal(t, "lab", lab.Name)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, lab.URLs)
+	assert.Equal(t, "admin", lab.Username)
+	assert.Equal(t, `apic#tacacs\admin`, lab.login())
+	assert.Equal(t, AuthPassword, lab.Auth)
+	assert.Equal(t, 120, lab.Timeout)
+	assert.Equal(t, Credentials{Env: "GOACI_TEST_PASSWORD"}, lab.Credentials)
+
+	prod, _ := cfg.Profile("prod")
+	assert.Equal(t, "automation", prod.login())
+	assert.Equal(t, 30, prod.Timeout)
+	assert.True(t, *prod.TLS.Verify)
+
+	_, err = cfg.Profile("empty")
+	assert.EqualError(t, err, "profile empty has no urls")
+	_, err = cfg.Profile("missing")
+	assert.Error(t, err)
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+	ioutil.WriteFile(path, []byte("profiles: ["), 0600)
+	_, err = Load(path)
+	assert.Error(t, err)
+}
+
+// TestProfileMerge tests merging the tls and certificate settings with the defaults field by field.
+func TestProfileMerge(t *testing.T) {
+	cfg, err := Parse([]byte(`
+defaults:
+  certificate:
+    key: default.key
+  tls:
+    verify: true
+    ca: ca.pem
+profiles:
+  lab:
+    urls: [10.0.0.1]
+    certificate:
+      name: automation
+    tls:
+      serverName: apic
+  insecure:
+    urls: [10.0.0.2]
+    tls:
+      verify: false
+`))
+	assert.NoError(t, err)
+	lab, err := cfg.Profile("lab")
+	assert.NoError(t, err)
+	assert.Equal(t, Certificate{Name: "automation", Key: "default.key"}, lab.Certificate)
+	assert.True(t, *lab.TLS.Verify)
+	assert.Equal(t, "ca.pem", lab.TLS.CA)
+	assert.Equal(t, "apic", lab.TLS.ServerName)
+
+	insecure, _ := cfg.Profile("insecure")
+	assert.False(t, *insecure.TLS.Verify)
+	assert.Equal(t, "ca.pem", insecure.TLS.CA)
+}
+
+// TestConfigClient tests creating logged in clients from profiles.
+func TestConfigClient(t *testing.T) {
+	defer gock.Off()
+	cfg, _ := Parse([]byte(testConfig))
+	os.Setenv("GOACI_TEST_PASSWORD", "secret")
+	defer os.Unsetenv("GOACI_TEST_PASSWORD")
+
+	// The second APIC is used if the first is unavailable
+	gock.New("https://10.0.0.1").Post("/api/aaaLogin.json").ReplyError(errors.New("unreachable"))
+	gock.New("https://10.0.0.2").
+		Post("/api/aaaLogin.json").
+		BodyString(`"name":"apic#tacacs\\\\admin","pwd":"secret"`).
+		Reply(200).
+		BodyString(`{"imdata":[{"aaaLogin":{"attributes":{"token":"token"}}}]}`)
+	client, err := cfg.Client("lab", intercept)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.2", client.Url)
+	assert.Equal(t, "token", client.Token)
+	assert.Equal(t, 120*time.Second, client.HttpClient.Timeout)
+	assert.True(t, gock.IsDone())
+
+	// All APICs fail
+	gock.New("https://10.0.0.1").Post("/api/aaaLogin.json").Reply(401)
+	gock.New("https://10.0.0.2").Post("/api/aaaLogin.json").Reply(401)
+	_, err = cfg.Client("lab", intercept)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "10.0.0.1: received HTTP status 401")
+		assert.Contains(t, err.Error(), "10.0.0.2: received HTTP status 401")
+	}
+
+	// Cached sessions are restored without logging in
+	dir, _ := ioutil.TempDir("", "goaci")
+	defer os.RemoveAll(dir)
+	cache := goaci.CacheSession(goaci.SessionFile(filepath.Join(dir, "sessions.json")))
+	gock.New("https://10.0.0.1").
+		Post("/api/aaaLogin.json").
+		Reply(200).
+		BodyString(`{"imdata":[{"aaaLogin":{"attributes":{"token":"token1"}}}]}`)
+	client, err = cfg.Client("lab", intercept, cache)
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	client, err = cfg.Client("lab", intercept, cache)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.1", client.Url)
+	assert.Equal(t, "token1", client.Token)
+	assert.False(t, gock.HasUnmatchedRequest())
+
+	// and log in again if the APIC rejects the session
+	gock.New("https://10.0.0.1").Get("/api/class/fvTenant.json").Reply(403)
+	gock.New("https://10.0.0.1").
+		Post("/api/aaaLogin.json").
+		Reply(200).
+		BodyString(`{"imdata":[{"aaaLogin":{"attributes":{"token":"token2"}}}]}`)
+	gock.New("https://10.0.0.1").Get("/api/class/fvTenant.json").Reply(200)
+	_, err = client.GetClass("fvTenant")
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, "token2", client.Token)
+
+	// Missing credentials
+	os.Unsetenv("GOACI_TEST_PASSWORD")
+	_, err = cfg.Client("lab", intercept)
+	assert.EqualError(t, err, "profile lab: environment variable GOACI_TEST_PASSWORD not set")
+}
+
+// TestProfileNewClient tests client settings from profiles.
+func TestProfileNewClient(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "goaci")
+	defer os.RemoveAll(dir)
+
+	// Certificate authentication and TLS verification
+	verify := true
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	keyFile := filepath.Join(dir, "automation.key")
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600)
+	prod := Profile{
+		Name:        "prod",
+		URLs:        []string{"apic.example.com"},
+		Username:    "automation",
+		Auth:        AuthCertificate,
+		Certificate: Certificate{Name: "automation", Key: keyFile},
+		TLS:         TLS{Verify: &verify, ServerName: "apic"},
+	}
+	client, err := prod.NewClient(prod.URLs[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "automation", client.CertName)
+	assert.Equal(t, key.N, client.Key.N)
+	assert.NoError(t, client.Login())
+	tlsConfig, _ := prod.tlsConfig()
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+	assert.Equal(t, "apic", tlsConfig.ServerName)
+
+	prod.Certificate.Key = ""
+	_, err = prod.NewClient(prod.URLs[0])
+	assert.Error(t, err)
+
+	// Verification is disabled by default
+	lab := Profile{Name: "lab", URLs: []string{"10.0.0.1"}, Username: "admin", Auth: AuthPassword, Credentials: Credentials{Env: "PATH"}}
+	tlsConfig, _ = lab.tlsConfig()
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+
+	// CA file
+	lab.TLS.CA = filepath.Join(dir, "ca.pem")
+	ioutil.WriteFile(lab.TLS.CA, []byte("not a certificate"), 0600)
+	_, err = lab.NewClient(lab.URLs[0])
+	assert.Error(t, err)
+
+	lab.TLS.CA = ""
+	lab.Auth = "kerberos"
+	_, err = lab.NewClient(lab.URLs[0])
+	assert.EqualError(t, err, "profile lab: unknown auth method kerberos")
+
+	lab.Auth = AuthPassword
+	lab.Username = ""
+	_, err = lab.NewClient(lab.URLs[0])
+	assert.EqualError(t, err, "profile lab has no username")
+}
@@ -0,0 +1,21 @@
+package goaci
+
+// Reader is the read API common to goaci.Client and backup.Client.
+// Use this to write code that runs against both a live fabric and a backup file, e.g.
+//  func tenants(r goaci.Reader) (Res, error) {
+//    return r.GetClass("fvTenant")
+//  }
+//
+//  tenants(&client) // goaci.Client
+//  tenants(bkup)    // backup.Client
+type Reader interface {
+	GetClass(class string, mods ...func(*Req)) (Res, error)
+	GetDn(dn string, mods ...func(*Req)) (Res, error)
+}
+
+// IsNotFound indicates whether an error is due to a missing class or DN, e.g. from backup.Client.GetDn.
+// Note that goaci.Client returns an empty result rather than an error in this case.
+func IsNotFound(err error) bool {
+	notFound, ok := err.(interface{ NotFound() bool })
+	return ok && notFound.NotFound()
+}
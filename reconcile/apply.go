@@ -0,0 +1,115 @@
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/brightpuddle/goaci"
+)
+
+// dependencies returns the DNs an object depends on, i.e. its parent and, for a named relation,
+// the possible targets in the source tenant and tenant common.
+func dependencies(change Change) []string {
+	deps := []string{string(goaci.DN(change.Dn).Parent())}
+	relation, ok := goaci.Relations[change.Class]
+	if !ok {
+		return deps
+	}
+	name := change.Attributes[relation.NameAttr]
+	if name == "" {
+		name = "default"
+	}
+	if rns := goaci.DN(change.Dn).RNs(); len(rns) > 1 {
+		deps = append(deps, "uni/"+rns[1]+"/"+relation.TargetRn+name)
+	}
+	return append(deps, "uni/tn-common/"+relation.TargetRn+name)
+}
+
+// order sorts changes in dependency order: creates and modifies with parents and relation
+// targets first, then deletes with children first.
+func order(changes []Change) []Change {
+	var writes, deletes []Change
+	index := make(map[string]int)
+	for _, change := range changes {
+		if change.Action == Delete {
+			deletes = append(deletes, change)
+			continue
+		}
+		index[change.Dn] = len(writes)
+		writes = append(writes, change)
+	}
+
+	// Depth-first topological sort, keeping the original order where possible
+	var ordered []Change
+	visited := make([]bool, len(writes))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for _, dep := range dependencies(writes[i]) {
+			if j, ok := index[dep]; ok {
+				visit(j)
+			}
+		}
+		ordered = append(ordered, writes[i])
+	}
+	for i := range writes {
+		visit(i)
+	}
+
+	sort.SliceStable(deletes, func(i, j int) bool {
+		return len(goaci.DN(deletes[i].Dn).RNs()) > len(goaci.DN(deletes[j].Dn).RNs())
+	})
+	return append(ordered, deletes...)
+}
+
+// body returns the POST body for a create or modify.
+func (change Change) body() goaci.Body {
+	body := goaci.Body{}.Set(change.Class+".attributes.dn", change.Dn)
+	names := make([]string, 0, len(change.Attributes))
+	for name := range change.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		body = body.Set(change.Class+".attributes."+name, change.Attributes[name])
+	}
+	for _, c := range change.Changes {
+		body = body.Set(change.Class+".attributes."+c.Name, c.New)
+	}
+	return body
+}
+
+// ApplyError is returned when a change fails to apply.
+type ApplyError struct {
+	// Applied is the number of changes applied before the failure.
+	Applied int
+	Change  Change
+	Err     error
+}
+
+// Error describes the failed change.
+func (err ApplyError) Error() string {
+	return fmt.Sprintf("%s %s: %v", err.Change.Action, err.Change.Dn, err.Err)
+}
+
+// Apply applies the changes in order, one request per change.
+// Applying stops at the first failure, returning an ApplyError; plan again to continue.
+// Pass a client in dry-run mode or a goaci.Recorder to record the requests instead.
+func (plan Plan) Apply(client goaci.Writer) error {
+	for i, change := range plan.Changes {
+		var err error
+		path := "/api/mo/" + change.Dn
+		if change.Action == Delete {
+			_, err = client.Delete(path)
+		} else {
+			_, err = client.Post(path, change.body().Str)
+		}
+		if err != nil {
+			return ApplyError{Applied: i, Change: change, Err: err}
+		}
+	}
+	return nil
+}
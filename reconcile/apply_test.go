@@ -0,0 +1,69 @@
+package reconcile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// TestPlanApply tests the Plan::Apply method.
+func TestPlanApply(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	plan := Plan{Changes: []Change{
+		{Action: Create, Dn: "uni/tn-a/BD-web", Class: "fvBD", Attributes: map[string]string{"name": "web"}},
+		{Action: Modify, Dn: "uni/tn-a/BD-db", Class: "fvBD", Changes: []AttributeChange{{"descr", "old", "new"}}},
+		{Action: Delete, Dn: "uni/tn-a/BD-old", Class: "fvBD"},
+	}}
+
+	gock.New(testURL).
+		Post("/api/mo/uni/tn-a/BD-web.json").
+		BodyString(`^{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-web","name":"web"}}}$`).
+		Reply(200)
+	gock.New(testURL).
+		Post("/api/mo/uni/tn-a/BD-db.json").
+		BodyString(`^{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-db","descr":"new"}}}$`).
+		Reply(200)
+	gock.New(testURL).Delete("/api/mo/uni/tn-a/BD-old.json").Reply(200)
+	assert.NoError(t, plan.Apply(&client))
+	assert.True(t, gock.IsDone())
+
+	// Dry run
+	rec := goaci.NewRecorder(&client)
+	assert.NoError(t, plan.Apply(rec))
+	assert.Len(t, rec.Changes.Changes, 3)
+	assert.Equal(t, "DELETE", rec.Changes.Changes[2].Method)
+
+	// Failure
+	gock.New(testURL).Post("/api/mo/uni/tn-a/BD-web.json").Reply(200)
+	gock.New(testURL).Post("/api/mo/uni/tn-a/BD-db.json").ReplyError(errors.New("fail"))
+	err := plan.Apply(&client)
+	applyErr, ok := err.(ApplyError)
+	if assert.True(t, ok) {
+		assert.Equal(t, 1, applyErr.Applied)
+		assert.Equal(t, "uni/tn-a/BD-db", applyErr.Change.Dn)
+	}
+}
+
+// TestIdempotent tests that planning after applying results in an empty plan.
+func TestIdempotent(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	desired := goaci.Body{}.
+		Set("fvTenant.attributes.dn", "uni/tn-a").
+		SetRaw("fvTenant.children.0", `{"fvBD":{"attributes":{"name":"web","descr":"new"}}}`).
+		Res()
+
+	// The APIC returns the applied configuration with additional attributes
+	gock.New(testURL).Get("/api/mo/uni/tn-a.json").Reply(200).BodyString(`{"imdata":[
+		{"fvTenant":{"attributes":{"dn":"uni/tn-a","name":"a"},"children":[
+			{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-web","name":"web","descr":"new","arpFlood":"no"}}}
+		]}}
+	]}`)
+	plan, err := NewPlan(&client, desired)
+	assert.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+}
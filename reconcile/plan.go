@@ -0,0 +1,259 @@
+// Package reconcile applies desired-state configuration to the APIC.
+// A plan compares a desired tree of managed objects with the current configuration, e.g.
+//  desired := goaci.Body{}.
+//    Set("fvTenant.attributes.dn", "uni/tn-a").
+//    SetRaw("fvTenant.children.0", goaci.Body{}.Set("fvBD.attributes.name", "web").Str).
+//    Res()
+//  plan, _ := reconcile.NewPlan(&client, desired)
+//  fmt.Print(plan)
+//  plan.Apply(&client)
+// Only attributes present in the desired tree are managed, so applying a plan and
+// planning again results in an empty plan.
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/brightpuddle/goaci/backup"
+)
+
+// Res is an alias of goaci.Res.
+type Res = goaci.Res
+
+// Actions
+const (
+	Create = "create"
+	Modify = "modify"
+	Delete = "delete"
+)
+
+// AttributeChange is a managed attribute with a different current value.
+type AttributeChange struct {
+	Name string `json:"name"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// Change is a planned change to a managed object.
+type Change struct {
+	// Action is Create, Modify or Delete.
+	Action string `json:"action"`
+	Dn     string `json:"dn"`
+	Class  string `json:"class"`
+	// Attributes are the attributes of a created object.
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Changes are the changed attributes of a modified object.
+	Changes []AttributeChange `json:"changes,omitempty"`
+}
+
+// Plan is the list of changes to reach the desired state, in the order they are applied.
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// Options are the options for planning.
+type Options struct {
+	// Prune deletes unmanaged objects, i.e. objects in the current configuration missing from the
+	// desired tree. Only objects of classes used in the desired tree are deleted, so e.g. BDs
+	// created outside the desired tree are removed, but system-created children are kept.
+	Prune bool
+}
+
+// Prune deletes unmanaged objects. See Options.Prune.
+func Prune(opts *Options) {
+	opts.Prune = true
+}
+
+// object is a managed object in a flattened tree.
+type object struct {
+	dn    string
+	class string
+	attrs map[string]string
+}
+
+// flatten returns the objects in a tree in pre-order, deriving DNs from the parent DN
+// and naming properties where missing.
+func flatten(obj Res, parentDn string) ([]object, error) {
+	class := goaci.ClassName(obj)
+	attrs := make(map[string]string)
+	obj.Get(class + ".attributes").ForEach(func(key, value Res) bool {
+		attrs[key.Str] = value.String()
+		return true
+	})
+	dn := attrs["dn"]
+	if dn == "" {
+		if parentDn == "" {
+			return nil, fmt.Errorf("%s has no dn", class)
+		}
+		rn := attrs["rn"]
+		if rn == "" {
+			var err error
+			if rn, err = backup.NewRn(class, attrs); err != nil {
+				return nil, err
+			}
+		}
+		dn = string(goaci.DN(parentDn).Child(rn))
+	}
+	delete(attrs, "dn")
+	delete(attrs, "rn")
+	objs := []object{{dn: dn, class: class, attrs: attrs}}
+	for _, child := range obj.Get(class + ".children").Array() {
+		children, err := flatten(child, dn)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, children...)
+	}
+	return objs, nil
+}
+
+// equalValues compares attribute values, ignoring the order of comma separated lists, e.g. public,shared.
+func equalValues(a, b string) bool {
+	if a == b {
+		return true
+	}
+	sa := strings.Split(a, ",")
+	sb := strings.Split(b, ",")
+	sort.Strings(sa)
+	sort.Strings(sb)
+	return strings.Join(sa, ",") == strings.Join(sb, ",")
+}
+
+// NewPlan compares a desired tree of managed objects with the current configuration.
+// The desired tree is in the APIC JSON format, and the top level object must have a dn.
+// Child DNs are derived from the naming properties. Set status to deleted to delete an object.
+func NewPlan(client *goaci.Client, desired Res, mods ...func(*Options)) (Plan, error) {
+	opts := Options{}
+	for _, mod := range mods {
+		mod(&opts)
+	}
+	want, err := flatten(desired, "")
+	if err != nil {
+		return Plan{}, err
+	}
+	top := want[0].dn
+
+	// Current configuration
+	res, err := client.GetDn(top,
+		goaci.Query("rsp-subtree", "full"),
+		goaci.Query("rsp-prop-include", "config-only"),
+	)
+	if err != nil {
+		return Plan{}, err
+	}
+	current := make(map[string]object)
+	var currentOrder []string
+	if res.Exists() {
+		objs, err := flatten(res, "")
+		if err != nil {
+			return Plan{}, err
+		}
+		for _, obj := range objs {
+			current[obj.dn] = obj
+			currentOrder = append(currentOrder, obj.dn)
+		}
+	}
+
+	var plan Plan
+	managed := make(map[string]bool)
+	classes := make(map[string]bool)
+	for _, obj := range want {
+		managed[obj.dn] = true
+		classes[obj.class] = true
+		cur, exists := current[obj.dn]
+		if obj.attrs["status"] == "deleted" {
+			if exists {
+				plan.Changes = append(plan.Changes, Change{Action: Delete, Dn: obj.dn, Class: obj.class})
+			}
+			continue
+		}
+		delete(obj.attrs, "status")
+		if !exists {
+			plan.Changes = append(plan.Changes, Change{Action: Create, Dn: obj.dn, Class: obj.class, Attributes: obj.attrs})
+			continue
+		}
+		var changes []AttributeChange
+		for name, value := range obj.attrs {
+			if !equalValues(value, cur.attrs[name]) {
+				changes = append(changes, AttributeChange{Name: name, Old: cur.attrs[name], New: value})
+			}
+		}
+		if len(changes) > 0 {
+			sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+			plan.Changes = append(plan.Changes, Change{Action: Modify, Dn: obj.dn, Class: obj.class, Changes: changes})
+		}
+	}
+
+	// Unmanaged objects, leaving out the subtrees of deleted objects
+	if opts.Prune {
+		var pruned []string
+		for _, dn := range currentOrder {
+			obj := current[dn]
+			if managed[dn] || !classes[obj.class] || underAny(dn, pruned) {
+				continue
+			}
+			pruned = append(pruned, dn)
+			plan.Changes = append(plan.Changes, Change{Action: Delete, Dn: dn, Class: obj.class})
+		}
+	}
+
+	plan.Changes = order(plan.Changes)
+	return plan, nil
+}
+
+// underAny indicates whether a DN is in the subtree of any of the given DNs.
+func underAny(dn string, dns []string) bool {
+	for _, parent := range dns {
+		if goaci.DN(parent).IsAncestorOf(goaci.DN(dn)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty indicates that the configuration is in the desired state.
+func (plan Plan) Empty() bool {
+	return len(plan.Changes) == 0
+}
+
+// JSON returns the plan as indented JSON.
+func (plan Plan) JSON() string {
+	data, _ := json.MarshalIndent(plan, "", "  ")
+	return string(data)
+}
+
+// String returns the plan as human-readable text, e.g.
+//  + fvBD uni/tn-a/BD-web
+//      name: "web"
+//  ~ fvBD uni/tn-a/BD-db
+//      descr: "old" -> "new"
+//  - fvBD uni/tn-a/BD-old
+func (plan Plan) String() string {
+	var text strings.Builder
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case Create:
+			fmt.Fprintf(&text, "+ %s %s\n", change.Class, change.Dn)
+			names := make([]string, 0, len(change.Attributes))
+			for name := range change.Attributes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Fprintf(&text, "    %s: %q\n", name, change.Attributes[name])
+			}
+		case Modify:
+			fmt.Fprintf(&text, "~ %s %s\n", change.Class, change.Dn)
+			for _, c := range change.Changes {
+				fmt.Fprintf(&text, "    %s: %q -> %q\n", c.Name, c.Old, c.New)
+			}
+		case Delete:
+			fmt.Fprintf(&text, "- %s %s\n", change.Class, change.Dn)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,134 @@
+package reconcile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brightpuddle/goaci"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const (
+	testHost = "10.0.0.1"
+	testURL  = "https://" + testHost
+)
+
+func testClient() goaci.Client {
+	client, _ := goaci.NewClient(testHost, "usr", "pwd")
+	client.LastRefresh = time.Now()
+	gock.InterceptClient(client.HttpClient)
+	return client
+}
+
+// currentTenant is the current configuration of uni/tn-a.
+const currentTenant = `{"imdata":[{"fvTenant":{"attributes":{"dn":"uni/tn-a","name":"a","descr":""},"children":[
+	{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-web","name":"web","descr":"old"},"children":[
+		{"fvRsCtx":{"attributes":{"rn":"rsctx","tnFvCtxName":"vrf"}}}
+	]}},
+	{"fvBD":{"attributes":{"dn":"uni/tn-a/BD-old","name":"old"},"children":[
+		{"fvSubnet":{"attributes":{"dn":"uni/tn-a/BD-old/subnet-[10.0.0.1/24]","ip":"10.0.0.1/24"}}}
+	]}},
+	{"fvRsTenantMonPol":{"attributes":{"dn":"uni/tn-a/rsTenantMonPol","tnMonEPGPolName":""}}}
+]}}]}`
+
+// desiredTenant is the desired configuration of uni/tn-a.
+var desiredTenant = gjson.Parse(`{"fvTenant":{"attributes":{"dn":"uni/tn-a","name":"a"},"children":[
+	{"fvAp":{"attributes":{"name":"app"},"children":[
+		{"fvAEPg":{"attributes":{"name":"web"},"children":[
+			{"fvRsBd":{"attributes":{"tnFvBDName":"db"}}}
+		]}}
+	]}},
+	{"fvBD":{"attributes":{"name":"db"},"children":[
+		{"fvRsCtx":{"attributes":{"tnFvCtxName":"vrf"}}}
+	]}},
+	{"fvBD":{"attributes":{"name":"web","descr":"new"},"children":[
+		{"fvRsCtx":{"attributes":{"tnFvCtxName":"vrf"}}}
+	]}},
+	{"fvCtx":{"attributes":{"name":"vrf"}}}
+]}}`)
+
+// actions returns the action and DN of each change.
+func actions(plan Plan) (result []string) {
+	for _, change := range plan.Changes {
+		result = append(result, change.Action+" "+change.Dn)
+	}
+	return result
+}
+
+// TestNewPlan tests the NewPlan function.
+func TestNewPlan(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+
+	// Dependency order
+	gock.New(testURL).
+		Get("/api/mo/uni/tn-a.json").
+		MatchParam("rsp-subtree", "full").
+		MatchParam("rsp-prop-include", "config-only").
+		Reply(200).
+		BodyString(currentTenant)
+	plan, err := NewPlan(&client, desiredTenant)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"create uni/tn-a/ap-app",
+		"create uni/tn-a/ap-app/epg-web",
+		"create uni/tn-a/BD-db",
+		"create uni/tn-a/ap-app/epg-web/rsbd",
+		"create uni/tn-a/ctx-vrf",
+		"create uni/tn-a/BD-db/rsctx",
+		"modify uni/tn-a/BD-web",
+	}, actions(plan))
+	assert.Equal(t, []AttributeChange{{Name: "descr", Old: "old", New: "new"}}, plan.Changes[6].Changes)
+	assert.Equal(t, map[string]string{"tnFvBDName": "db"}, plan.Changes[3].Attributes)
+
+	// Pruning unmanaged objects of managed classes
+	gock.New(testURL).Get("/api/mo/uni/tn-a.json").Reply(200).BodyString(currentTenant)
+	plan, _ = NewPlan(&client, desiredTenant, Prune)
+	assert.Equal(t, "delete uni/tn-a/BD-old", actions(plan)[len(plan.Changes)-1])
+	assert.Len(t, plan.Changes, 8)
+
+	// Explicit delete
+	gock.New(testURL).Get("/api/mo/uni/tn-a.json").Reply(200).BodyString(currentTenant)
+	desired := goaci.Body{}.
+		Set("fvTenant.attributes.dn", "uni/tn-a").
+		SetRaw("fvTenant.children.0", `{"fvBD":{"attributes":{"name":"old","status":"deleted"}}}`).
+		SetRaw("fvTenant.children.1", `{"fvBD":{"attributes":{"name":"gone","status":"deleted"}}}`).
+		Res()
+	plan, _ = NewPlan(&client, desired)
+	assert.Equal(t, []string{"delete uni/tn-a/BD-old"}, actions(plan))
+
+	// New tenant
+	gock.New(testURL).Get("/api/mo/uni/tn-b.json").Reply(200).BodyString(`{"imdata":[]}`)
+	plan, _ = NewPlan(&client, goaci.Body{}.Set("fvTenant.attributes.dn", "uni/tn-b").Res())
+	assert.Equal(t, []string{"create uni/tn-b"}, actions(plan))
+
+	// No DN
+	_, err = NewPlan(&client, goaci.Body{}.Set("fvTenant.attributes.name", "a").Res())
+	assert.Error(t, err)
+}
+
+// TestEqualValues tests the equalValues function.
+func TestEqualValues(t *testing.T) {
+	assert.True(t, equalValues("public,shared", "shared,public"))
+	assert.False(t, equalValues("public", "public,shared"))
+}
+
+// TestPlanString tests the Plan::String method.
+func TestPlanString(t *testing.T) {
+	plan := Plan{Changes: []Change{
+		{Action: Create, Dn: "uni/tn-a/BD-web", Class: "fvBD", Attributes: map[string]string{"name": "web"}},
+		{Action: Modify, Dn: "uni/tn-a/BD-db", Class: "fvBD", Changes: []AttributeChange{{"descr", "old", "new"}}},
+		{Action: Delete, Dn: "uni/tn-a/BD-old", Class: "fvBD"},
+	}}
+	assert.Equal(t, `+ fvBD uni/tn-a/BD-web
+    name: "web"
+~ fvBD uni/tn-a/BD-db
+    descr: "old" -> "new"
+- fvBD uni/tn-a/BD-old
+`, plan.String())
+	assert.Equal(t, "modify", gjson.Get(plan.JSON(), "changes.1.action").Str)
+	assert.False(t, plan.Empty())
+	assert.True(t, Plan{}.Empty())
+}
@@ -0,0 +1,53 @@
+package goaci
+
+// Writer is the write API of goaci.Client.
+// Use this to write change scripts that can run in dry-run mode against a backup with a Recorder.
+type Writer interface {
+	Post(path, data string, mods ...func(*Req)) (Res, error)
+	Delete(path string, mods ...func(*Req)) (Res, error)
+}
+
+// ReadWriter is the read and write API of goaci.Client.
+type ReadWriter interface {
+	Reader
+	Writer
+}
+
+// DryRun records POST and DELETE requests in Client.Changes instead of sending them.
+// Reads are still sent to the APIC, e.g.
+//  client, _ := goaci.NewClient("apic", "user", "password", goaci.DryRun)
+//  client.Post("/api/mo/uni/tn-a", body.Str)
+//  client.Changes.WriteFile("changes.json")
+func DryRun(client *Client) {
+	client.DryRun = true
+}
+
+// dryRunRes is the result returned for recorded requests.
+var dryRunRes = Body{Str: `{"imdata":[]}`}.Res()
+
+// Recorder records POST and DELETE requests, passing reads to a Reader, e.g. a backup.Client:
+//  rec := goaci.NewRecorder(bkup)
+//  myChangeScript(rec)
+//  fmt.Print(rec.Changes)
+type Recorder struct {
+	Reader
+	// Changes are the recorded requests.
+	Changes ChangeSet
+}
+
+// NewRecorder creates a recorder reading from a backup or live fabric.
+func NewRecorder(r Reader) *Recorder {
+	return &Recorder{Reader: r}
+}
+
+// Post records a POST request.
+func (rec *Recorder) Post(path, data string, mods ...func(*Req)) (Res, error) {
+	rec.Changes.add("POST", path, data, mods...)
+	return dryRunRes, nil
+}
+
+// Delete records a DELETE request.
+func (rec *Recorder) Delete(path string, mods ...func(*Req)) (Res, error) {
+	rec.Changes.add("DELETE", path, "", mods...)
+	return dryRunRes, nil
+}
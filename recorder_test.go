@@ -0,0 +1,34 @@
+package goaci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// TestRecorder tests the Recorder type.
+func TestRecorder(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	rec := NewRecorder(&client)
+
+	// Reads are passed through
+	gock.New(testURL).
+		Get("/api/class/fvTenant.json").
+		Reply(200).
+		BodyString(Body{}.Set("imdata.0.fvTenant.attributes.name", "a").Str)
+	res, err := rec.GetClass("fvTenant")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", res.Get("0.fvTenant.attributes.name").Str)
+
+	// Writes are recorded
+	var w ReadWriter = rec
+	_, err = w.Post("/api/mo/uni/tn-a", `{"fvTenant":{"attributes":{"descr":"x"}}}`)
+	assert.NoError(t, err)
+	_, err = w.Delete("/api/mo/uni/tn-b")
+	assert.NoError(t, err)
+	assert.Len(t, rec.Changes.Changes, 2)
+	assert.Empty(t, client.Changes.Changes)
+	assert.False(t, gock.HasUnmatchedRequest())
+}
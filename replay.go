@@ -0,0 +1,262 @@
+package goaci
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/brightpuddle/goaci/meta"
+	"gopkg.in/yaml.v3"
+)
+
+// stringify converts a YAML node to JSON values with string scalars, since APIC attribute values
+// are strings. Scalars keep their literal text, e.g. 1.10 or 0x1F.
+func stringify(node *yaml.Node) interface{} {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return stringify(node.Content[0])
+	case yaml.MappingNode:
+		v := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v[node.Content[i].Value] = stringify(node.Content[i+1])
+		}
+		return v
+	case yaml.SequenceNode:
+		v := make([]interface{}, len(node.Content))
+		for i, child := range node.Content {
+			v[i] = stringify(child)
+		}
+		return v
+	case yaml.AliasNode:
+		return stringify(node.Alias)
+	default:
+		if node.Tag == "!!null" {
+			return ""
+		}
+		return node.Value
+	}
+}
+
+// splitQuery splits query parameters from a recorded path into request modifiers.
+func splitQuery(path string) (string, []func(*Req)) {
+	i := strings.Index(path, "?")
+	if i < 0 {
+		return path, nil
+	}
+	values, _ := url.ParseQuery(path[i+1:])
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var mods []func(*Req)
+	for _, key := range keys {
+		for _, value := range values[key] {
+			mods = append(mods, Query(key, value))
+		}
+	}
+	return path[:i], mods
+}
+
+// ParseChangeSet parses a change set in JSON or YAML format, e.g.
+//  changes:
+//    - method: POST
+//      dn: uni/tn-a
+//      body:
+//        fvTenant:
+//          attributes:
+//            descr: Managed
+//    - method: DELETE
+//      dn: uni/tn-b
+// The path defaults to /api/mo/<dn>.
+func ParseChangeSet(data []byte) (ChangeSet, error) {
+	var doc struct {
+		Changes []struct {
+			Method string    `yaml:"method"`
+			Path   string    `yaml:"path"`
+			Dn     string    `yaml:"dn"`
+			Body   yaml.Node `yaml:"body"`
+		} `yaml:"changes"`
+	}
+	// JSON is valid YAML
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return ChangeSet{}, err
+	}
+	var cs ChangeSet
+	for i, c := range doc.Changes {
+		change := Change{Method: strings.ToUpper(c.Method), Path: c.Path, Dn: c.Dn}
+		if change.Path == "" && change.Dn != "" {
+			change.Path = "/api/mo/" + change.Dn
+		}
+		if change.Dn == "" {
+			change.Dn = pathDn(change.Path)
+		}
+		if c.Body.Kind != 0 && c.Body.Tag != "!!null" {
+			if c.Body.Kind != yaml.MappingNode {
+				return ChangeSet{}, fmt.Errorf("step %d: body is not an object", i)
+			}
+			body, err := json.MarshalIndent(stringify(&c.Body), "", "  ")
+			if err != nil {
+				return ChangeSet{}, err
+			}
+			change.Body = body
+		}
+		cs.Changes = append(cs.Changes, change)
+	}
+	return cs, nil
+}
+
+// ReadChangeSet reads a change set file in JSON or YAML format.
+// See ParseChangeSet for the format.
+func ReadChangeSet(path string) (ChangeSet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ChangeSet{}, err
+	}
+	cs, err := ParseChangeSet(data)
+	if err != nil {
+		return ChangeSet{}, fmt.Errorf("%s: %v", filepath.Base(path), err)
+	}
+	return cs, nil
+}
+
+// Validate checks the change set before replay: methods, paths and bodies.
+// Bodies are also checked against the metadata models if any are registered (see Body.Validate).
+// All problems are returned at once.
+func (cs ChangeSet) Validate() error {
+	var problems []string
+	schema := len(meta.Versions()) > 0
+	for i, change := range cs.Changes {
+		add := func(format string, args ...interface{}) {
+			problems = append(problems, fmt.Sprintf("step %d: %s", i, fmt.Sprintf(format, args...)))
+		}
+		if !strings.HasPrefix(change.Path, "/") {
+			add("missing path or dn")
+		}
+		switch change.Method {
+		case "DELETE":
+			if len(change.Body) > 0 {
+				add("DELETE has a body")
+			}
+		case "POST":
+			body := Body{Str: string(change.Body)}
+			if !body.Res().IsObject() {
+				add("POST body is not a JSON object")
+				continue
+			}
+			if path, _ := splitQuery(change.Path); schema && strings.HasPrefix(path, "/api/mo/") {
+				if err := body.Validate(parentClass(path, body)...); err != nil {
+					add("%v", err)
+				}
+			}
+		default:
+			add("invalid method %q", change.Method)
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// ReplayOptions are the options for replaying a change set.
+type ReplayOptions struct {
+	// ContinueOnError continues with the next step after a failure.
+	// By default replay stops at the first failure.
+	ContinueOnError bool
+	// Start is the index of the first step to run, i.e. the resume point of a previous replay.
+	Start int
+}
+
+// ContinueOnError continues replay after a failed step.
+func ContinueOnError(opts *ReplayOptions) {
+	opts.ContinueOnError = true
+}
+
+// StartAt resumes replay at a step index, e.g. the Resume index of a failed replay.
+func StartAt(step int) func(*ReplayOptions) {
+	return func(opts *ReplayOptions) {
+		opts.Start = step
+	}
+}
+
+// StepResult is the result of a replayed step.
+type StepResult struct {
+	// Step is the index of the change in the change set.
+	Step   int    `json:"step"`
+	Change Change `json:"change"`
+	// Skipped indicates the step was before the start index or after a failure.
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Res is the APIC response.
+	Res Res `json:"-"`
+}
+
+// ReplayResult is the result of a replay with a result per step.
+type ReplayResult struct {
+	Steps []StepResult `json:"steps"`
+	// Resume is the index of the first failed step, to continue after fixing the failure
+	// with StartAt. Resume is -1 if all steps succeeded.
+	Resume int `json:"resume"`
+}
+
+// Replay runs the changes in order through a client, e.g.
+//  cs, _ := goaci.ReadChangeSet("changes.yaml")
+//  result, err := cs.Replay(&client)
+//  if err != nil {
+//    // Fix the failure, then continue
+//    result, err = cs.Replay(&client, goaci.StartAt(result.Resume))
+//  }
+// The change set is validated first, and nothing is sent if it is invalid.
+func (cs ChangeSet) Replay(client Writer, mods ...func(*ReplayOptions)) (ReplayResult, error) {
+	opts := ReplayOptions{}
+	for _, mod := range mods {
+		mod(&opts)
+	}
+	result := ReplayResult{Resume: -1}
+	if err := cs.Validate(); err != nil {
+		return result, err
+	}
+	if opts.Start < 0 || opts.Start > len(cs.Changes) {
+		return result, fmt.Errorf("invalid start step %d", opts.Start)
+	}
+
+	failed := 0
+	for i, change := range cs.Changes {
+		step := StepResult{Step: i, Change: change}
+		if i < opts.Start || (failed > 0 && !opts.ContinueOnError) {
+			step.Skipped = true
+			result.Steps = append(result.Steps, step)
+			continue
+		}
+		path, mods := splitQuery(change.Path)
+		var err error
+		if change.Method == "DELETE" {
+			step.Res, err = client.Delete(path, mods...)
+		} else {
+			step.Res, err = client.Post(path, string(change.Body), mods...)
+		}
+		if err != nil {
+			step.Error = err.Error()
+			if failed == 0 {
+				result.Resume = i
+			}
+			failed++
+		}
+		result.Steps = append(result.Steps, step)
+	}
+	if failed > 0 {
+		first := result.Steps[result.Resume]
+		return result, fmt.Errorf("%d steps failed, first at step %d (%s %s): %s",
+			failed, first.Step, first.Change.Method, first.Change.Path, first.Error)
+	}
+	return result, nil
+}
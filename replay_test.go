@@ -0,0 +1,174 @@
+package goaci
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const testChangesYaml = `changes:
+  - method: post
+    dn: uni/tn-a
+    body:
+      fvTenant:
+        attributes:
+          name: a
+          descr: 1
+          nameAlias: 1.10
+          annotation: 0x1F
+          ownerKey: ~
+  - method: DELETE
+    path: /api/mo/uni/tn-b
+`
+
+// TestParseChangeSet tests parsing change sets from JSON and YAML.
+func TestParseChangeSet(t *testing.T) {
+	cs, err := ParseChangeSet([]byte(testChangesYaml))
+	assert.NoError(t, err)
+	if assert.Len(t, cs.Changes, 2) {
+		assert.Equal(t, "POST", cs.Changes[0].Method)
+		assert.Equal(t, "/api/mo/uni/tn-a", cs.Changes[0].Path)
+		attrs := Body{Str: string(cs.Changes[0].Body)}.Res().Get("fvTenant.attributes")
+		assert.Equal(t, "1", attrs.Get("descr").Str)
+		assert.Equal(t, "1.10", attrs.Get("nameAlias").Str)
+		assert.Equal(t, "0x1F", attrs.Get("annotation").Str)
+		assert.Equal(t, "", attrs.Get("ownerKey").Str)
+		assert.Equal(t, Change{Method: "DELETE", Path: "/api/mo/uni/tn-b", Dn: "uni/tn-b"}, cs.Changes[1])
+	}
+
+	// JSON, e.g. from ChangeSet.WriteFile
+	var recorded ChangeSet
+	recorded.add("POST", "/api/mo/uni/tn-a", `{"fvTenant":{"attributes":{"name":"a"}}}`)
+	cs, err = ParseChangeSet([]byte(recorded.JSON()))
+	assert.NoError(t, err)
+	assert.Equal(t, recorded, cs)
+
+	_, err = ParseChangeSet([]byte("changes: ["))
+	assert.Error(t, err)
+
+	// Body not an object
+	_, err = ParseChangeSet([]byte("changes:\n  - method: POST\n    dn: uni/tn-a\n    body: [a]\n"))
+	assert.EqualError(t, err, "step 0: body is not an object")
+}
+
+// TestReadChangeSet tests reading change set files.
+func TestReadChangeSet(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "goaci")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "changes.yaml")
+	ioutil.WriteFile(path, []byte(testChangesYaml), 0644)
+
+	cs, err := ReadChangeSet(path)
+	assert.NoError(t, err)
+	assert.Len(t, cs.Changes, 2)
+
+	_, err = ReadChangeSet(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+// TestChangeSetValidate tests validating change sets before replay.
+func TestChangeSetValidate(t *testing.T) {
+	cs, _ := ParseChangeSet([]byte(testChangesYaml))
+	assert.NoError(t, cs.Validate())
+
+	invalid := ChangeSet{Changes: []Change{
+		{Method: "GET", Path: "/api/mo/uni"},
+		{Method: "POST", Path: "/api/mo/uni", Body: []byte(`"text"`)},
+		{Method: "DELETE"},
+	}}
+	err := invalid.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `step 0: invalid method "GET"`)
+		assert.Contains(t, err.Error(), "step 1: POST body is not a JSON object")
+		assert.Contains(t, err.Error(), "step 2: missing path or dn")
+	}
+
+	// Schema validation with metadata
+	defer registerTestMeta(t)()
+	schema := ChangeSet{Changes: []Change{
+		{Method: "POST", Path: "/api/mo/uni/tn-a", Body: []byte(`{"fvTenant":{"attributes":{"name":"a","bogus":"x"}}}`)},
+	}}
+	err = schema.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "step 0:")
+		assert.Contains(t, err.Error(), "bogus")
+	}
+}
+
+// TestChangeSetReplay tests the ChangeSet::Replay method.
+func TestChangeSetReplay(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	cs := ChangeSet{Changes: []Change{
+		{Method: "POST", Path: "/api/mo/uni/tn-a", Body: []byte(`{"fvTenant":{"attributes":{"name":"a"}}}`)},
+		{Method: "POST", Path: "/api/mo/uni/tn-b", Body: []byte(`{"fvTenant":{"attributes":{"name":"b"}}}`)},
+		{Method: "DELETE", Path: "/api/mo/uni/tn-c?rsp-subtree=no"},
+	}}
+
+	// Success
+	gock.New(testURL).Post("/api/mo/uni/tn-a.json").BodyString(`^\{"fvTenant":\{"attributes":\{"name":"a"\}\}\}$`).Reply(200)
+	gock.New(testURL).Post("/api/mo/uni/tn-b.json").Reply(200)
+	gock.New(testURL).Delete("/api/mo/uni/tn-c.json").MatchParam("rsp-subtree", "no").Reply(200)
+	result, err := cs.Replay(&client)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, result.Resume)
+	assert.Len(t, result.Steps, 3)
+	assert.True(t, gock.IsDone())
+
+	// Stop at the first failure
+	gock.New(testURL).Post("/api/mo/uni/tn-a.json").Reply(200)
+	gock.New(testURL).Post("/api/mo/uni/tn-b.json").ReplyError(errors.New("fail"))
+	result, err = cs.Replay(&client)
+	assert.Error(t, err)
+	assert.Equal(t, 1, result.Resume)
+	assert.Equal(t, "", result.Steps[0].Error)
+	assert.NotEqual(t, "", result.Steps[1].Error)
+	assert.True(t, result.Steps[2].Skipped)
+	assert.True(t, gock.IsDone())
+
+	// Resume at the failed step
+	gock.New(testURL).Post("/api/mo/uni/tn-b.json").Reply(200)
+	gock.New(testURL).Delete("/api/mo/uni/tn-c.json").Reply(200)
+	result, err = cs.Replay(&client, StartAt(result.Resume))
+	assert.NoError(t, err)
+	assert.True(t, result.Steps[0].Skipped)
+	assert.Equal(t, -1, result.Resume)
+	assert.True(t, gock.IsDone())
+
+	// Continue on error
+	gock.New(testURL).Post("/api/mo/uni/tn-a.json").Reply(405)
+	gock.New(testURL).Post("/api/mo/uni/tn-b.json").Reply(200)
+	gock.New(testURL).Delete("/api/mo/uni/tn-c.json").Reply(405)
+	result, err = cs.Replay(&client, ContinueOnError)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "2 steps failed, first at step 0")
+	}
+	assert.Equal(t, 0, result.Resume)
+	assert.Equal(t, "", result.Steps[1].Error)
+	assert.False(t, result.Steps[2].Skipped)
+	assert.True(t, gock.IsDone())
+
+	// Invalid start
+	_, err = cs.Replay(&client, StartAt(4))
+	assert.Error(t, err)
+
+	// Invalid change sets aren't sent
+	invalid := ChangeSet{Changes: append(cs.Changes, Change{Method: "PUT", Path: "/api/mo/uni"})}
+	_, err = invalid.Replay(&client)
+	assert.Error(t, err)
+	assert.False(t, gock.HasUnmatchedRequest())
+}
+
+// TestReplayRecorder tests replaying through a dry-run recorder.
+func TestReplayRecorder(t *testing.T) {
+	cs, _ := ParseChangeSet([]byte(testChangesYaml))
+	recorder := NewRecorder(nil)
+	_, err := cs.Replay(recorder)
+	assert.NoError(t, err)
+	assert.Equal(t, cs, recorder.Changes)
+}
@@ -42,6 +42,8 @@ type Req struct {
 	// Refresh indicates whether token refresh should be checked for this request.
 	// Pass NoRefresh to disable Refresh check.
 	Refresh bool
+	// Progress is called as file transfers progress. See goaci.Progress.
+	Progress func(done, total int64)
 }
 
 // NoRefresh prevents token refresh check.
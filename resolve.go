@@ -0,0 +1,212 @@
+package goaci
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Relation describes a named relation class, i.e. a relation referencing its target by name.
+type Relation struct {
+	// NameAttr is the attribute holding the target name, e.g. tnFvBDName.
+	NameAttr string
+	// TargetClass is the class of the target, e.g. fvBD.
+	TargetClass string
+	// TargetRn is the RN prefix of the target, e.g. BD-.
+	TargetRn string
+}
+
+// Relations are the named relation classes known to the resolver, by relation class.
+// Add entries to resolve other relation classes.
+var Relations = map[string]Relation{
+	"fvRsBd":           {"tnFvBDName", "fvBD", "BD-"},
+	"fvRsCtx":          {"tnFvCtxName", "fvCtx", "ctx-"},
+	"l3extRsEctx":      {"tnFvCtxName", "fvCtx", "ctx-"},
+	"fvRsCons":         {"tnVzBrCPName", "vzBrCP", "brc-"},
+	"fvRsProv":         {"tnVzBrCPName", "vzBrCP", "brc-"},
+	"fvRsIntraEpg":     {"tnVzBrCPName", "vzBrCP", "brc-"},
+	"vzRsAnyToCons":    {"tnVzBrCPName", "vzBrCP", "brc-"},
+	"vzRsAnyToProv":    {"tnVzBrCPName", "vzBrCP", "brc-"},
+	"fvRsConsIf":       {"tnVzCPIfName", "vzCPIf", "cif-"},
+	"vzRsAnyToConsIf":  {"tnVzCPIfName", "vzCPIf", "cif-"},
+	"fvRsProtBy":       {"tnVzTabooName", "vzTaboo", "taboo-"},
+	"vzRsSubjFiltAtt":  {"tnVzFilterName", "vzFilter", "flt-"},
+	"vzRsFiltAtt":      {"tnVzFilterName", "vzFilter", "flt-"},
+	"vzRsDenyRule":     {"tnVzFilterName", "vzFilter", "flt-"},
+	"fvRsBDToOut":      {"tnL3extOutName", "l3extOut", "out-"},
+	"fvRsIgmpsn":       {"tnIgmpSnoopPolName", "igmpSnoopPol", "snPol-"},
+	"fvRsBdToEpRet":    {"tnFvEpRetPolName", "fvEpRetPol", "epRPol-"},
+	"fvRsCtxToEpRet":   {"tnFvEpRetPolName", "fvEpRetPol", "epRPol-"},
+	"fvRsOspfCtxPol":   {"tnOspfCtxPolName", "ospfCtxPol", "ospfCtxP-"},
+	"fvRsBgpCtxPol":    {"tnBgpCtxPolName", "bgpCtxPol", "bgpCtxP-"},
+	"fvRsCustQosPol":   {"tnQosCustomPolName", "qosCustomPol", "qoscustom-"},
+	"vzRsSubjGraphAtt": {"tnVnsAbsGraphName", "vnsAbsGraph", "AbsGraph-"},
+	"fvRsTenantMonPol": {"tnMonEPGPolName", "monEPGPol", "monepg-"},
+	"fvRsAEPgMonPol":   {"tnMonEPGPolName", "monEPGPol", "monepg-"},
+	"fvRsBDToNdP":      {"tnNdIfPolName", "ndIfPol", "ndifpol-"},
+}
+
+// Resolution is the result of resolving a relation object.
+type Resolution struct {
+	// Source is the DN of the relation object.
+	Source string `json:"source"`
+	// Class is the relation class, e.g. fvRsBd.
+	Class string `json:"class"`
+	// Name is the target name, if the relation is by name.
+	Name string `json:"name,omitempty"`
+	// Target is the DN of the target.
+	// For an unresolved relation, this is the DN of the target in the source tenant.
+	Target string `json:"target"`
+	// Resolved indicates whether the target exists.
+	Resolved bool `json:"resolved"`
+	// Shadowed are targets with the same name hidden by the resolved target,
+	// i.e. an object in tenant common hidden by an object in the source tenant.
+	Shadowed []string `json:"shadowed,omitempty"`
+}
+
+// Resolver resolves relation objects to their targets following ACI name resolution,
+// i.e. the source tenant first, then tenant common. An empty name resolves to default.
+// Use NewResolver to create a resolver.
+type Resolver struct {
+	reader Reader
+	exists map[string]bool
+}
+
+// NewResolver creates a resolver for a backup or live fabric, e.g.
+//  resolver := goaci.NewResolver(bkup)
+//  bd, _ := resolver.Follow("uni/tn-a/ap-app/epg-web", "fvRsBd")
+//  vrf, _ := resolver.Follow(bd.Target, "fvRsCtx")
+// Lookups are cached, so create a new resolver to pick up configuration changes.
+func NewResolver(r Reader) Resolver {
+	return Resolver{
+		reader: r,
+		exists: make(map[string]bool),
+	}
+}
+
+// tenantOf returns the tenant DN for a DN, or an empty string for DNs outside a tenant.
+func tenantOf(dn string) string {
+	rns := DN(dn).RNs()
+	if len(rns) < 2 || rns[0] != "uni" || !strings.HasPrefix(rns[1], "tn-") {
+		return ""
+	}
+	return "uni/" + rns[1]
+}
+
+// lookup checks whether a DN exists, with caching.
+func (resolver Resolver) lookup(dn string) (bool, error) {
+	if found, ok := resolver.exists[dn]; ok {
+		return found, nil
+	}
+	res, err := resolver.reader.GetDn(dn)
+	if err != nil && !IsNotFound(err) {
+		return false, err
+	}
+	found := err == nil && res.Exists()
+	resolver.exists[dn] = found
+	return found, nil
+}
+
+// Resolve resolves a relation object, e.g. an fvRsBd object from GetClass.
+// Relations not listed in Relations resolve to their tDn attribute.
+func (resolver Resolver) Resolve(rel Res) (Resolution, error) {
+	class := ClassName(rel)
+	attrs := rel.Get(class + ".attributes")
+	res := Resolution{
+		Source: attrs.Get("dn").Str,
+		Class:  class,
+	}
+	relation, ok := Relations[class]
+	if !ok {
+		res.Target = attrs.Get("tDn").Str
+		if res.Target == "" {
+			return res, fmt.Errorf("%s is not a known relation", class)
+		}
+		found, err := resolver.lookup(res.Target)
+		res.Resolved = found
+		return res, err
+	}
+
+	res.Name = attrs.Get(relation.NameAttr).Str
+	if res.Name == "" {
+		res.Name = "default"
+	}
+	var candidates []string
+	if tenant := tenantOf(res.Source); tenant != "" && tenant != "uni/tn-common" {
+		candidates = append(candidates, tenant)
+	}
+	candidates = append(candidates, "uni/tn-common")
+	for _, tenant := range candidates {
+		dn := tenant + "/" + relation.TargetRn + res.Name
+		found, err := resolver.lookup(dn)
+		if err != nil {
+			return res, err
+		}
+		switch {
+		case found && !res.Resolved:
+			res.Target = dn
+			res.Resolved = true
+		case found:
+			res.Shadowed = append(res.Shadowed, dn)
+		}
+	}
+	if !res.Resolved {
+		res.Target = candidates[0] + "/" + relation.TargetRn + res.Name
+	}
+	return res, nil
+}
+
+// Follow resolves the relation of a class under an object, e.g.
+//  resolver.Follow("uni/tn-a/BD-bd", "fvRsCtx")
+// returns the resolution for the VRF of the BD.
+func (resolver Resolver) Follow(dn, relClass string) (Resolution, error) {
+	res, err := resolver.reader.GetDn(dn,
+		Query("query-target", "children"),
+		Query("target-subtree-class", relClass),
+	)
+	if err != nil {
+		return Resolution{}, err
+	}
+	if res.IsArray() {
+		res = res.Get("0")
+	}
+	if !res.Exists() {
+		return Resolution{}, fmt.Errorf("%s has no %s", dn, relClass)
+	}
+	return resolver.Resolve(res)
+}
+
+// ResolveAll resolves all objects of the given relation classes.
+// All classes in Relations are resolved if none are provided.
+// Results are sorted by source DN.
+func (resolver Resolver) ResolveAll(classes ...string) ([]Resolution, error) {
+	if len(classes) == 0 {
+		for class := range Relations {
+			classes = append(classes, class)
+		}
+	}
+	var results []Resolution
+	for _, class := range classes {
+		objs, err := resolver.reader.GetClass(class)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs.Array() {
+			res, err := resolver.Resolve(obj)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, res)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Source == results[j].Source {
+			return results[i].Class < results[j].Class
+		}
+		return results[i].Source < results[j].Source
+	})
+	return results, nil
+}
@@ -0,0 +1,95 @@
+package goaci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// mockDn mocks a GetDn request returning an object, or no object if class is empty.
+func mockDn(dn, class string, attrs map[string]string) {
+	body := Body{Str: `{"imdata":[]}`}
+	if class != "" {
+		body = body.Set("imdata.0."+class+".attributes.dn", dn)
+		for k, v := range attrs {
+			body = body.Set("imdata.0."+class+".attributes."+k, v)
+		}
+	}
+	gock.New(testURL).Get("/api/mo/" + dn + ".json").Reply(200).BodyString(body.Str)
+}
+
+// TestResolverResolve tests the Resolver::Resolve method.
+func TestResolverResolve(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	resolver := NewResolver(&client)
+
+	// Tenant before common
+	mockDn("uni/tn-a/BD-bd", "fvBD", nil)
+	mockDn("uni/tn-common/BD-bd", "fvBD", nil)
+	rel := Body{}.
+		Set("fvRsBd.attributes.dn", "uni/tn-a/ap-app/epg-web/rsbd").
+		Set("fvRsBd.attributes.tnFvBDName", "bd").
+		Res()
+	res, err := resolver.Resolve(rel)
+	assert.NoError(t, err)
+	assert.True(t, res.Resolved)
+	assert.Equal(t, "uni/tn-a/BD-bd", res.Target)
+	assert.Equal(t, []string{"uni/tn-common/BD-bd"}, res.Shadowed)
+
+	// Fall back to common, with an empty name resolving to default
+	mockDn("uni/tn-a/ctx-default", "", nil)
+	mockDn("uni/tn-common/ctx-default", "fvCtx", nil)
+	rel = Body{}.Set("fvRsCtx.attributes.dn", "uni/tn-a/BD-bd/rsctx").Res()
+	res, _ = resolver.Resolve(rel)
+	assert.Equal(t, "uni/tn-common/ctx-default", res.Target)
+	assert.Equal(t, "default", res.Name)
+	assert.Empty(t, res.Shadowed)
+
+	// Unresolved, using cached lookups
+	rel = Body{}.
+		Set("fvRsCtx.attributes.dn", "uni/tn-a/BD-other/rsctx").
+		Set("fvRsCtx.attributes.tnFvCtxName", "missing").
+		Res()
+	mockDn("uni/tn-a/ctx-missing", "", nil)
+	mockDn("uni/tn-common/ctx-missing", "", nil)
+	res, _ = resolver.Resolve(rel)
+	assert.False(t, res.Resolved)
+	assert.Equal(t, "uni/tn-a/ctx-missing", res.Target)
+	res, _ = resolver.Resolve(rel)
+	assert.False(t, res.Resolved)
+
+	// Explicit target DN
+	mockDn("uni/infra/attentp-aep", "infraAttEntityP", nil)
+	rel = Body{}.
+		Set("infraRsAttEntP.attributes.dn", "uni/infra/funcprof/accportgrp-pg/rsattEntP").
+		Set("infraRsAttEntP.attributes.tDn", "uni/infra/attentp-aep").
+		Res()
+	res, _ = resolver.Resolve(rel)
+	assert.True(t, res.Resolved)
+	assert.Equal(t, "uni/infra/attentp-aep", res.Target)
+
+	// Unknown relation
+	_, err = resolver.Resolve(Body{}.Set("fvTenant.attributes.dn", "uni/tn-a").Res())
+	assert.Error(t, err)
+}
+
+// TestResolverFollow tests the Resolver::Follow method.
+func TestResolverFollow(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	resolver := NewResolver(&client)
+
+	mockDn("uni/tn-a/ap-app/epg-web", "fvRsBd", map[string]string{"tnFvBDName": "bd"})
+	mockDn("uni/tn-a/BD-bd", "fvBD", nil)
+	mockDn("uni/tn-common/BD-bd", "", nil)
+	res, err := resolver.Follow("uni/tn-a/ap-app/epg-web", "fvRsBd")
+	assert.NoError(t, err)
+	assert.Equal(t, "uni/tn-a/BD-bd", res.Target)
+
+	// No relation
+	mockDn("uni/tn-a/ap-app/epg-none", "", nil)
+	_, err = resolver.Follow("uni/tn-a/ap-app/epg-none", "fvRsBd")
+	assert.Error(t, err)
+}
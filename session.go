@@ -0,0 +1,185 @@
+package goaci
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// sessionTimeout is the APIC token lifetime, i.e. the default timeout of the APIC web token.
+const sessionTimeout = 600 * time.Second
+
+// Session is an APIC session, i.e. the token and cookies of a logged in client.
+type Session struct {
+	Url         string            `json:"url"`
+	Usr         string            `json:"usr"`
+	Token       string            `json:"token"`
+	LastRefresh time.Time         `json:"lastRefresh"`
+	Cookies     map[string]string `json:"cookies,omitempty"`
+}
+
+// SessionStore persists sessions across process runs. See CacheSession.
+type SessionStore interface {
+	// Load returns the session for a URL and user, or an empty session if there is none.
+	Load(url, usr string) (Session, error)
+	// Save saves a session, replacing the session of the same URL and user.
+	Save(session Session) error
+}
+
+// SessionFile is a session store in a JSON file, only readable by the user.
+// Sessions of multiple fabrics and users can be stored in the same file.
+type SessionFile string
+
+// sessions reads the sessions in the file.
+func (file SessionFile) sessions() ([]Session, error) {
+	data, err := ioutil.ReadFile(string(file))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var sessions []Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Load returns the session for a URL and user.
+func (file SessionFile) Load(url, usr string) (Session, error) {
+	sessions, err := file.sessions()
+	if err != nil {
+		return Session{}, err
+	}
+	for _, session := range sessions {
+		if session.Url == url && session.Usr == usr {
+			return session, nil
+		}
+	}
+	return Session{}, nil
+}
+
+// Save saves a session. Expired sessions are removed from the file.
+// The file is replaced atomically, so concurrent processes don't read partial files.
+func (file SessionFile) Save(session Session) error {
+	sessions, err := file.sessions()
+	if err != nil {
+		return err
+	}
+	kept := []Session{session}
+	for _, s := range sessions {
+		if (s.Url != session.Url || s.Usr != session.Usr) && time.Since(s.LastRefresh) < sessionTimeout {
+			kept = append(kept, s)
+		}
+	}
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(string(file))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, ".session")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// TempFile creates the file with 0600 permissions
+	return os.Rename(tmp.Name(), string(file))
+}
+
+// CacheSession reuses sessions across process runs, e.g. for short-lived CLI invocations:
+//  store := goaci.SessionFile(filepath.Join(home, ".goaci", "sessions.json"))
+//  client, _ := goaci.NewClient("apic", "user", "password", goaci.CacheSession(store))
+//  client.Get(...) // no login if a session was cached
+// NewClient restores a still valid session of the same URL and user. The restored token is used
+// until it is due for a refresh, as with a new login. If the APIC rejects it, e.g. after a logout,
+// the client logs in and retries the request.
+// Sessions are saved after each login and refresh.
+func CacheSession(store SessionStore) func(*Client) {
+	return func(client *Client) {
+		client.SessionStore = store
+	}
+}
+
+// Session returns the current session of the client.
+func (client *Client) Session() Session {
+	session := Session{
+		Url:         client.Url,
+		Usr:         client.Usr,
+		Token:       client.Token,
+		LastRefresh: client.LastRefresh,
+		Cookies:     make(map[string]string),
+	}
+	// Login cookies default to the /api path. Cookies with the most specific path come first,
+	// i.e. cookies from a new login before restored cookies.
+	if u, err := url.Parse(client.Url + "/api/"); err == nil && client.HttpClient.Jar != nil {
+		for _, cookie := range client.HttpClient.Jar.Cookies(u) {
+			if _, ok := session.Cookies[cookie.Name]; !ok {
+				session.Cookies[cookie.Name] = cookie.Value
+			}
+		}
+	}
+	return session
+}
+
+// verifySession verifies a restored session with a token refresh, logging in again if the APIC
+// rejects it, and otherwise refreshes the token if due, e.g. before concurrent requests.
+func (client *Client) verifySession() error {
+	if !client.restored || client.Key != nil {
+		return client.checkRefresh()
+	}
+	if err := client.Refresh(); err != nil {
+		if client.Pwd == "" {
+			return err
+		}
+		return client.Login()
+	}
+	return nil
+}
+
+// restoreSession restores a valid session from the session store.
+func (client *Client) restoreSession() error {
+	session, err := client.SessionStore.Load(client.Url, client.Usr)
+	if err != nil {
+		return err
+	}
+	if session.Token == "" || time.Since(session.LastRefresh) >= sessionTimeout {
+		return nil
+	}
+	client.Token = session.Token
+	client.LastRefresh = session.LastRefresh
+	client.restored = true
+	if u, err := url.Parse(client.Url); err == nil && client.HttpClient.Jar != nil {
+		var cookies []*http.Cookie
+		for name, value := range session.Cookies {
+			cookies = append(cookies, &http.Cookie{Name: name, Value: value, Path: "/"})
+		}
+		if len(cookies) == 0 {
+			// The APIC also accepts the token as cookie
+			cookies = append(cookies, &http.Cookie{Name: "APIC-cookie", Value: session.Token, Path: "/"})
+		}
+		client.HttpClient.Jar.SetCookies(u, cookies)
+	}
+	return nil
+}
+
+// saveSession saves the session to the session store, if any.
+func (client *Client) saveSession() error {
+	if client.SessionStore == nil {
+		return nil
+	}
+	return client.SessionStore.Save(client.Session())
+}
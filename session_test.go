@@ -0,0 +1,186 @@
+package goaci
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// testStore creates a session file in a temporary directory.
+// Returns a function to remove it.
+func testStore(t *testing.T) (SessionFile, func()) {
+	dir, err := ioutil.TempDir("", "goaci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return SessionFile(filepath.Join(dir, "goaci", "sessions.json")), func() {
+		os.RemoveAll(dir)
+	}
+}
+
+// TestSessionFile tests saving and loading sessions.
+func TestSessionFile(t *testing.T) {
+	store, remove := testStore(t)
+	defer remove()
+
+	session, err := store.Load(testURL, "usr")
+	assert.NoError(t, err)
+	assert.Equal(t, Session{}, session)
+
+	a := Session{Url: testURL, Usr: "usr", Token: "a", LastRefresh: time.Now().Round(time.Second)}
+	b := Session{Url: "https://10.0.0.2", Usr: "usr", Token: "b", LastRefresh: time.Now().Round(time.Second)}
+	expired := Session{Url: "https://10.0.0.3", Usr: "usr", Token: "c", LastRefresh: time.Now().Add(-time.Hour)}
+	assert.NoError(t, store.Save(expired))
+	assert.NoError(t, store.Save(a))
+	assert.NoError(t, store.Save(b))
+	a.Token = "updated"
+	assert.NoError(t, store.Save(a))
+
+	info, err := os.Stat(string(store))
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	sessions, _ := store.sessions()
+	assert.Len(t, sessions, 2)
+	session, _ = store.Load(testURL, "usr")
+	assert.Equal(t, "updated", session.Token)
+	assert.True(t, a.LastRefresh.Equal(session.LastRefresh))
+	session, _ = store.Load("https://10.0.0.2", "usr")
+	assert.Equal(t, "b", session.Token)
+	session, _ = store.Load("https://10.0.0.3", "usr")
+	assert.Equal(t, "", session.Token)
+
+	// Corrupt file
+	ioutil.WriteFile(string(store), []byte("{"), 0600)
+	_, err = store.Load(testURL, "usr")
+	assert.Error(t, err)
+	_, err = NewClient(testHost, "usr", "pwd", CacheSession(store))
+	assert.Error(t, err)
+}
+
+// TestCacheSession tests reusing sessions across clients.
+func TestCacheSession(t *testing.T) {
+	defer gock.Off()
+	store, remove := testStore(t)
+	defer remove()
+	newClient := func() Client {
+		client, err := NewClient(testHost, "usr", "pwd", CacheSession(store))
+		assert.NoError(t, err)
+		gock.InterceptClient(client.HttpClient)
+		return client
+	}
+
+	// Login saves the session
+	client := newClient()
+	assert.Equal(t, "", client.Token)
+	gock.New(testURL).
+		Post("/api/aaaLogin.json").
+		Reply(200).
+		SetHeader("Set-Cookie", "APIC-cookie=token1").
+		BodyString(`{"imdata":[{"aaaLogin":{"attributes":{"token":"token1"}}}]}`)
+	assert.NoError(t, client.Login())
+	session, _ := store.Load(testURL, "usr")
+	assert.Equal(t, "token1", session.Token)
+	assert.Equal(t, map[string]string{"APIC-cookie": "token1"}, session.Cookies)
+
+	// A new client reuses the session without refreshing it
+	client = newClient()
+	assert.Equal(t, "token1", client.Token)
+	u, _ := url.Parse(testURL + "/api/class/fvTenant")
+	assert.Len(t, client.HttpClient.Jar.Cookies(u), 1)
+	gock.New(testURL).
+		Get("/api/class/fvTenant.json").
+		MatchHeader("Cookie", "APIC-cookie=token1").
+		Reply(200)
+	_, err := client.GetClass("fvTenant")
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+
+	// The restored token is refreshed when due
+	client = newClient()
+	client.LastRefresh = time.Now().Add(-481 * time.Second)
+	gock.New(testURL).
+		Get("/api/aaaRefresh.json").
+		MatchHeader("Cookie", "APIC-cookie=token1").
+		Reply(200).
+		BodyString(`{"imdata":[{"aaaRefresh":{"attributes":{"token":"token2"}}}]}`)
+	gock.New(testURL).Get("/api/class/fvBD.json").Reply(200)
+	_, err = client.GetClass("fvBD")
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	session, _ = store.Load(testURL, "usr")
+	assert.Equal(t, "token2", session.Token)
+
+	// A rejected session falls back to login, and the request is retried with the new cookie
+	client = newClient()
+	body := `{"fvTenant":{"attributes":{"name":"a"}}}`
+	gock.New(testURL).Post("/api/mo/uni/tn-a.json").Reply(403)
+	gock.New(testURL).
+		Post("/api/aaaLogin.json").
+		Reply(200).
+		SetHeader("Set-Cookie", "APIC-cookie=token3").
+		BodyString(`{"imdata":[{"aaaLogin":{"attributes":{"token":"token3"}}}]}`)
+	gock.New(testURL).
+		Post("/api/mo/uni/tn-a.json").
+		MatchHeader("Cookie", "^APIC-cookie=token3;").
+		BodyString(regexp.QuoteMeta(body)).
+		Reply(200)
+	_, err = client.Post("/api/mo/uni/tn-a", body)
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, "token3", client.Token)
+	session, _ = store.Load(testURL, "usr")
+	assert.Equal(t, map[string]string{"APIC-cookie": "token3"}, session.Cookies)
+
+	// Once verified, the session isn't replaced on a 403
+	gock.New(testURL).Get("/api/class/aaaUser.json").Reply(403)
+	_, err = client.GetClass("aaaUser")
+	assert.EqualError(t, err, "received HTTP status 403")
+	assert.True(t, gock.IsDone())
+
+	// Expired sessions aren't restored
+	session, _ = store.Load(testURL, "usr")
+	session.LastRefresh = time.Now().Add(-sessionTimeout)
+	store.Save(session)
+	client = newClient()
+	assert.Equal(t, "", client.Token)
+
+	// Sessions are per user
+	client, _ = NewClient(testHost, "other", "pwd", CacheSession(store))
+	assert.Equal(t, "", client.Token)
+
+	// Sessions without cookies use the token
+	store.Save(Session{Url: testURL, Usr: "usr", Token: "token4", LastRefresh: time.Now()})
+	client = newClient()
+	gock.New(testURL).
+		Get("/api/aaaRefresh.json").
+		MatchHeader("Cookie", "APIC-cookie=token4").
+		Reply(200)
+	assert.NoError(t, client.Refresh())
+	assert.True(t, gock.IsDone())
+
+	// Batches verify a restored session once before the concurrent queries
+	store.Save(Session{Url: testURL, Usr: "usr", Token: "token4", LastRefresh: time.Now()})
+	client = newClient()
+	gock.New(testURL).Get("/api/aaaRefresh.json").Reply(403)
+	gock.New(testURL).
+		Post("/api/aaaLogin.json").
+		Reply(200).
+		SetHeader("Set-Cookie", "APIC-cookie=token5").
+		BodyString(`{"imdata":[{"aaaLogin":{"attributes":{"token":"token5"}}}]}`)
+	gock.New(testURL).Get("/api/class/fvTenant.json").MatchHeader("Cookie", "^APIC-cookie=token5").Reply(200)
+	gock.New(testURL).Get("/api/class/fvBD.json").MatchHeader("Cookie", "^APIC-cookie=token5").Reply(200)
+	results, err := client.Batch([]BatchQuery{ClassQuery("fvTenant"), ClassQuery("fvBD")})
+	assert.NoError(t, err)
+	assert.NoError(t, results["fvTenant"].Err)
+	assert.NoError(t, results["fvBD"].Err)
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, "token5", client.Token)
+}
@@ -0,0 +1,253 @@
+package goaci
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+)
+
+// Job is an export or import job, i.e. a configJob object.
+type Job struct {
+	Dn string `json:"dn"`
+	// OperSt is the job state, e.g. pending, running, success or failed.
+	OperSt   string `json:"operSt"`
+	Details  string `json:"details"`
+	LastStep string `json:"lastStepDescr"`
+	// FileName is the exported or imported file.
+	FileName string `json:"fileName"`
+}
+
+// JobError is returned when an export or import job fails.
+type JobError struct {
+	// Policy is the DN of the export or import policy.
+	Policy string
+	Job    Job
+}
+
+func (e JobError) Error() string {
+	msg := fmt.Sprintf("job for %s: %s", e.Policy, e.Job.OperSt)
+	if e.Job.Details != "" {
+		msg += ": " + e.Job.Details
+	}
+	return msg
+}
+
+// JobOptions are the options for waiting on export and import jobs.
+type JobOptions struct {
+	// PollInterval is the time between job status checks. Defaults to 2 seconds.
+	PollInterval time.Duration
+	// Timeout is the maximum time to wait for the job. Defaults to 10 minutes.
+	Timeout time.Duration
+}
+
+// PollInterval modifies the time between job status checks.
+func PollInterval(x time.Duration) func(*JobOptions) {
+	return func(opts *JobOptions) {
+		opts.PollInterval = x
+	}
+}
+
+// JobTimeout modifies the maximum time to wait for a job.
+func JobTimeout(x time.Duration) func(*JobOptions) {
+	return func(opts *JobOptions) {
+		opts.Timeout = x
+	}
+}
+
+// Snapshot is a configuration snapshot, i.e. a configSnapshot object.
+type Snapshot struct {
+	Dn         string `json:"dn"`
+	Name       string `json:"name"`
+	Descr      string `json:"descr"`
+	FileName   string `json:"fileName"`
+	CreateTime string `json:"createTime"`
+}
+
+func newJob(res Res) Job {
+	attrs := res.Get("configJob.attributes")
+	return Job{
+		Dn:       attrs.Get("dn").Str,
+		OperSt:   attrs.Get("operSt").Str,
+		Details:  attrs.Get("details").Str,
+		LastStep: attrs.Get("lastStepDescr").Str,
+		FileName: attrs.Get("fileName").Str,
+	}
+}
+
+func newSnapshot(res Res) Snapshot {
+	attrs := res.Get("configSnapshot.attributes")
+	return Snapshot{
+		Dn:         attrs.Get("dn").Str,
+		Name:       attrs.Get("name").Str,
+		Descr:      attrs.Get("descr").Str,
+		FileName:   attrs.Get("fileName").Str,
+		CreateTime: attrs.Get("createTime").Str,
+	}
+}
+
+// jobs returns the jobs of an export or import policy.
+func (client *Client) jobs(policy string) ([]Job, error) {
+	res, err := client.Get("/api/mo/uni/backupst/jobs-["+policy+"]",
+		Query("query-target", "children"),
+		Query("target-subtree-class", "configJob"))
+	if err != nil {
+		return nil, err
+	}
+	var jobs []Job
+	for _, record := range res.Get("imdata").Array() {
+		jobs = append(jobs, newJob(record))
+	}
+	return jobs, nil
+}
+
+// trigger posts a triggered export or import policy and waits for the new job to complete.
+// In dry-run mode the policy is recorded and an empty job is returned without waiting.
+func (client *Client) trigger(policy string, body Body, mods ...func(*JobOptions)) (Job, error) {
+	opts := JobOptions{
+		PollInterval: 2 * time.Second,
+		Timeout:      10 * time.Minute,
+	}
+	for _, mod := range mods {
+		mod(&opts)
+	}
+	if client.DryRun {
+		_, err := client.Post("/api/mo/"+policy, body.Str)
+		return Job{}, err
+	}
+
+	// Previous runs of the policy are kept, so note them to find the new job
+	previous := make(map[string]bool)
+	jobs, err := client.jobs(policy)
+	if err != nil {
+		return Job{}, err
+	}
+	for _, job := range jobs {
+		previous[job.Dn] = true
+	}
+	if _, err := client.Post("/api/mo/"+policy, body.Str); err != nil {
+		return Job{}, err
+	}
+
+	deadline := time.Now().Add(opts.Timeout)
+	for {
+		jobs, err := client.jobs(policy)
+		if err != nil {
+			return Job{}, err
+		}
+		for _, job := range jobs {
+			if previous[job.Dn] {
+				continue
+			}
+			switch job.OperSt {
+			case "pending", "running":
+			case "success":
+				return job, nil
+			default:
+				return job, JobError{Policy: policy, Job: job}
+			}
+		}
+		if time.Now().After(deadline) {
+			return Job{}, fmt.Errorf("timeout waiting for job for %s", policy)
+		}
+		time.Sleep(opts.PollInterval)
+	}
+}
+
+// Export triggers a one-time export of the fabric configuration to a remote location,
+// i.e. a fileRemotePath in uni/fabric, and waits for the export job to complete, e.g.
+//  job, err := client.Export("nightly", "backup-server")
+//  fmt.Println(job.FileName)
+func (client *Client) Export(name, remotePath string, mods ...func(*JobOptions)) (Job, error) {
+	policy := "uni/fabric/configexp-" + name
+	body := Body{}.
+		Set("configExportP.attributes.dn", policy).
+		Set("configExportP.attributes.name", name).
+		Set("configExportP.attributes.format", "json").
+		Set("configExportP.attributes.snapshot", "no").
+		Set("configExportP.attributes.adminSt", "triggered").
+		SetRaw("configExportP.children.0", Body{}.
+			Set("configRsRemotePath.attributes.tnFileRemotePathName", remotePath).Str)
+	return client.trigger(policy, body, mods...)
+}
+
+// TakeSnapshot triggers a one-time configuration snapshot on the APIC and waits for it to complete, e.g.
+//  snapshot, err := client.TakeSnapshot("pre-change", "before upgrade")
+// The snapshot is stored under the export policy uni/fabric/configexp-<name>.
+func (client *Client) TakeSnapshot(name, descr string, mods ...func(*JobOptions)) (Snapshot, error) {
+	policy := "uni/fabric/configexp-" + name
+	body := Body{}.
+		Set("configExportP.attributes.dn", policy).
+		Set("configExportP.attributes.name", name).
+		Set("configExportP.attributes.descr", descr).
+		Set("configExportP.attributes.format", "json").
+		Set("configExportP.attributes.snapshot", "yes").
+		Set("configExportP.attributes.adminSt", "triggered")
+	job, err := client.trigger(policy, body, mods...)
+	if err != nil || client.DryRun {
+		return Snapshot{}, err
+	}
+	res, err := client.Get("/api/mo/uni/backupst/snapshots-["+policy+"]",
+		Query("query-target", "children"),
+		Query("target-subtree-class", "configSnapshot"))
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for _, record := range res.Get("imdata").Array() {
+		if snapshot := newSnapshot(record); snapshot.FileName == job.FileName {
+			return snapshot, nil
+		}
+	}
+	return Snapshot{}, fmt.Errorf("snapshot %s not found", job.FileName)
+}
+
+// Snapshots returns the configuration snapshots on the APIC, oldest first.
+func (client *Client) Snapshots(mods ...func(*Req)) ([]Snapshot, error) {
+	res, err := client.GetClass("configSnapshot", mods...)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []Snapshot
+	for _, record := range res.Array() {
+		snapshots = append(snapshots, newSnapshot(record))
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreateTime < snapshots[j].CreateTime
+	})
+	return snapshots, nil
+}
+
+// DiffSnapshots compares two snapshots by DN using the APIC snapshot diff endpoint,
+// /mqapi2/snapshots.diff.xml. The endpoint only serves XML, so the raw XML diff is returned.
+func (client *Client) DiffSnapshots(dn1, dn2 string, mods ...func(*Req)) (string, error) {
+	mods = append([]func(*Req){Query("s1dn", dn1), Query("s2dn", dn2)}, mods...)
+	req := client.newReq("GET", client.Url+"/mqapi2/snapshots.diff.xml", nil, mods...)
+	httpRes, err := client.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer httpRes.Body.Close()
+	body, err := ioutil.ReadAll(httpRes.Body)
+	if err != nil {
+		return "", errors.New("cannot read response body")
+	}
+	return string(body), nil
+}
+
+// Rollback triggers an atomic replace import of a snapshot, using the import policy
+// uni/fabric/configimp-<name>, and waits for the import job to complete, e.g.
+//  snapshots, _ := client.Snapshots()
+//  job, err := client.Rollback("rollback", snapshots[len(snapshots)-1])
+func (client *Client) Rollback(name string, snapshot Snapshot, mods ...func(*JobOptions)) (Job, error) {
+	policy := "uni/fabric/configimp-" + name
+	body := Body{}.
+		Set("configImportP.attributes.dn", policy).
+		Set("configImportP.attributes.name", name).
+		Set("configImportP.attributes.fileName", snapshot.FileName).
+		Set("configImportP.attributes.snapshot", "yes").
+		Set("configImportP.attributes.importType", "replace").
+		Set("configImportP.attributes.importMode", "atomic").
+		Set("configImportP.attributes.adminSt", "triggered")
+	return client.trigger(policy, body, mods...)
+}
@@ -0,0 +1,179 @@
+package goaci
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// mockJobs mocks the job list of a policy.
+func mockJobs(policy string, jobs ...Job) {
+	body := Body{Str: `{"imdata":[]}`}
+	for i, job := range jobs {
+		body = body.
+			Set(fmt.Sprintf("imdata.%d.configJob.attributes.dn", i), job.Dn).
+			Set(fmt.Sprintf("imdata.%d.configJob.attributes.operSt", i), job.OperSt).
+			Set(fmt.Sprintf("imdata.%d.configJob.attributes.details", i), job.Details).
+			Set(fmt.Sprintf("imdata.%d.configJob.attributes.fileName", i), job.FileName)
+	}
+	gock.New(testURL).
+		Get(regexp.QuoteMeta("/api/mo/uni/backupst/jobs-["+policy+"].json")).
+		MatchParam("target-subtree-class", "configJob").
+		Reply(200).
+		BodyString(body.Str)
+}
+
+// TestClientTakeSnapshot tests the Client::TakeSnapshot method.
+func TestClientTakeSnapshot(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	policy := "uni/fabric/configexp-pre"
+	old := Job{Dn: "uni/backupst/jobs-[" + policy + "]/run-1", OperSt: "success", FileName: "old.tar.gz"}
+	run := Job{Dn: "uni/backupst/jobs-[" + policy + "]/run-2", FileName: "new.tar.gz"}
+
+	// Success after polling
+	mockJobs(policy, old)
+	gock.New(testURL).
+		Post("/api/mo/uni/fabric/configexp-pre.json").
+		BodyString(`"snapshot":"yes","adminSt":"triggered"`).
+		Reply(200)
+	mockJobs(policy, old)
+	run.OperSt = "running"
+	mockJobs(policy, old, run)
+	run.OperSt = "success"
+	mockJobs(policy, old, run)
+	gock.New(testURL).
+		Get(regexp.QuoteMeta("/api/mo/uni/backupst/snapshots-[" + policy + "].json")).
+		Reply(200).
+		BodyString(Body{}.
+			Set("imdata.0.configSnapshot.attributes.dn", "snap-old").
+			Set("imdata.0.configSnapshot.attributes.fileName", "old.tar.gz").
+			Set("imdata.1.configSnapshot.attributes.dn", "snap-new").
+			Set("imdata.1.configSnapshot.attributes.fileName", "new.tar.gz").
+			Str)
+	snapshot, err := client.TakeSnapshot("pre", "before", PollInterval(time.Millisecond))
+	assert.NoError(t, err)
+	assert.Equal(t, "snap-new", snapshot.Dn)
+	assert.True(t, gock.IsDone())
+
+	// Job failure
+	mockJobs(policy, old)
+	gock.New(testURL).Post("/api/mo/uni/fabric/configexp-pre.json").Reply(200)
+	run.OperSt = "failed"
+	run.Details = "disk full"
+	mockJobs(policy, old, run)
+	_, err = client.TakeSnapshot("pre", "before", PollInterval(time.Millisecond))
+	if assert.Error(t, err) {
+		assert.Equal(t, "job for uni/fabric/configexp-pre: failed: disk full", err.Error())
+		assert.Equal(t, "disk full", err.(JobError).Job.Details)
+	}
+	assert.True(t, gock.IsDone())
+
+	// Timeout
+	mockJobs(policy)
+	gock.New(testURL).Post("/api/mo/uni/fabric/configexp-pre.json").Reply(200)
+	mockJobs(policy)
+	_, err = client.TakeSnapshot("pre", "", JobTimeout(0))
+	assert.Error(t, err)
+
+	// HTTP error
+	gock.New(testURL).
+		Get(regexp.QuoteMeta("/api/mo/uni/backupst/jobs-[" + policy + "].json")).
+		ReplyError(errors.New("fail"))
+	_, err = client.TakeSnapshot("pre", "")
+	assert.Error(t, err)
+}
+
+// TestClientExport tests the Client::Export method.
+func TestClientExport(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	policy := "uni/fabric/configexp-nightly"
+	run := Job{Dn: "run-1", OperSt: "success", FileName: "nightly.tar.gz"}
+
+	mockJobs(policy)
+	gock.New(testURL).
+		Post("/api/mo/uni/fabric/configexp-nightly.json").
+		BodyString(`"tnFileRemotePathName":"server"`).
+		Reply(200)
+	mockJobs(policy, run)
+	job, err := client.Export("nightly", "server", PollInterval(time.Millisecond))
+	assert.NoError(t, err)
+	assert.Equal(t, run, job)
+	assert.True(t, gock.IsDone())
+
+	// Dry run records the policy without waiting
+	DryRun(&client)
+	_, err = client.Export("nightly", "server")
+	assert.NoError(t, err)
+	assert.Len(t, client.Changes.Changes, 1)
+}
+
+// TestClientSnapshots tests the Client::Snapshots method.
+func TestClientSnapshots(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+
+	gock.New(testURL).
+		Get("/api/class/configSnapshot.json").
+		Reply(200).
+		BodyString(Body{}.
+			Set("imdata.0.configSnapshot.attributes.dn", "b").
+			Set("imdata.0.configSnapshot.attributes.createTime", "2020-02-01T00:00:00.000+00:00").
+			Set("imdata.1.configSnapshot.attributes.dn", "a").
+			Set("imdata.1.configSnapshot.attributes.createTime", "2020-01-01T00:00:00.000+00:00").
+			Str)
+	snapshots, err := client.Snapshots()
+	assert.NoError(t, err)
+	if assert.Len(t, snapshots, 2) {
+		assert.Equal(t, "a", snapshots[0].Dn)
+		assert.Equal(t, "b", snapshots[1].Dn)
+	}
+
+	gock.New(testURL).Get("/api/class/configSnapshot.json").ReplyError(errors.New("fail"))
+	_, err = client.Snapshots()
+	assert.Error(t, err)
+}
+
+// TestClientDiffSnapshots tests the Client::DiffSnapshots method.
+func TestClientDiffSnapshots(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+
+	gock.New(testURL).
+		Get("/mqapi2/snapshots.diff.xml").
+		MatchParam("s1dn", regexp.QuoteMeta("snap-[a]")).
+		MatchParam("s2dn", "snap-b").
+		Reply(200).
+		BodyString(`<?xml version="1.0" encoding="UTF-8"?><imdata totalCount="0"></imdata>`)
+	diff, err := client.DiffSnapshots("snap-[a]", "snap-b")
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?><imdata totalCount="0"></imdata>`, diff)
+	assert.True(t, gock.IsDone())
+
+	gock.New(testURL).Get("/mqapi2/snapshots.diff.xml").Reply(400)
+	_, err = client.DiffSnapshots("snap-a", "snap-b")
+	assert.Error(t, err)
+}
+
+// TestClientRollback tests the Client::Rollback method.
+func TestClientRollback(t *testing.T) {
+	defer gock.Off()
+	client := testClient()
+	policy := "uni/fabric/configimp-rollback"
+
+	mockJobs(policy)
+	gock.New(testURL).
+		Post("/api/mo/uni/fabric/configimp-rollback.json").
+		BodyString(`"fileName":"snap.tar.gz".*"importType":"replace","importMode":"atomic"`).
+		Reply(200)
+	mockJobs(policy, Job{Dn: "run-1", OperSt: "success"})
+	_, err := client.Rollback("rollback", Snapshot{FileName: "snap.tar.gz"}, PollInterval(time.Millisecond))
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+}
@@ -0,0 +1,196 @@
+package goaci
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/brightpuddle/goaci/meta"
+)
+
+// Violation is a schema violation in a POST body.
+type Violation struct {
+	// Path is the GJSON path of the offending object in the body, e.g. fvTenant.children.0.fvBD.
+	Path    string `json:"path"`
+	Class   string `json:"class"`
+	Message string `json:"message"`
+}
+
+// ValidationError is the list of schema violations in a POST body.
+type ValidationError []Violation
+
+// Error lists the violations.
+func (err ValidationError) Error() string {
+	lines := make([]string, len(err))
+	for i, v := range err {
+		lines[i] = fmt.Sprintf("%s: %s", v.Path, v.Message)
+	}
+	return "invalid body:\n  " + strings.Join(lines, "\n  ")
+}
+
+// ValidatePosts validates POST bodies against the registered metadata models before sending, e.g.
+//  client, _ := goaci.NewClient("apic", "user", "password", goaci.ValidatePosts)
+// See Body.Validate for the checks. Only /api/mo requests are validated.
+// This needs the metadata models, see meta.LoadFile; NewClient returns meta.ErrNoModels otherwise.
+func ValidatePosts(client *Client) {
+	client.ValidatePosts = true
+}
+
+// rnClasses returns the classes whose RN format may match an RN.
+// This only compares the text before the first naming property, so the result may include extra classes.
+func rnClasses(rn string) (classes []string) {
+	seen := make(map[string]bool)
+	for _, version := range meta.Versions() {
+		model, _ := meta.Get(version)
+		for name, class := range model.Classes {
+			format := class.RnFormat
+			prefix := format
+			if i := strings.Index(format, "{"); i >= 0 {
+				prefix = strings.TrimSuffix(format[:i], "[")
+			}
+			matches := (prefix == format && format == rn) || (prefix != format && prefix != "" && strings.HasPrefix(rn, prefix))
+			if matches && !seen[name] {
+				seen[name] = true
+				classes = append(classes, name)
+			}
+		}
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// parentClass returns the possible classes of the parent of an object posted to a path,
+// e.g. polUni for fvTenant posted to /api/mo/uni.
+func parentClass(path string, body Body) []string {
+	dn := strings.TrimSuffix(strings.TrimPrefix(path, "/api/mo/"), ".json")
+	rns := DN(dn).RNs()
+	class := ClassName(body.Res())
+	top := body.Res().Get(class + ".attributes")
+
+	// Posting the object itself to its own DN, e.g. fvTenant to /api/mo/uni/tn-a
+	if top.Get("dn").Str == dn || (len(rns) > 1 && rnMatches(rns[len(rns)-1], class, top)) {
+		rns = rns[:len(rns)-1]
+	}
+	if len(rns) == 0 {
+		return nil
+	}
+	return rnClasses(rns[len(rns)-1])
+}
+
+// rnMatches indicates whether an RN is the RN of an object of a class.
+func rnMatches(rn, class string, attrs Res) bool {
+	c, ok := meta.Lookup(class)
+	if !ok || c.RnFormat == "" {
+		return false
+	}
+	expected := c.RnFormat
+	for _, name := range c.Naming {
+		value := attrs.Get(name).Str
+		expected = strings.Replace(expected, "{"+name+"}", value, 1)
+		expected = strings.Replace(expected, "{["+name+"]}", "["+value+"]", 1)
+	}
+	return expected == rn
+}
+
+// validate checks an object and its children.
+func validate(obj Res, path string, parents []string, violations *ValidationError) {
+	class := ClassName(obj)
+	path = strings.TrimPrefix(path+"."+class, ".")
+	add := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Path: path, Class: class, Message: fmt.Sprintf(format, args...)})
+	}
+	c, ok := meta.Lookup(class)
+	if !ok {
+		add("unknown class %s", class)
+		return
+	}
+
+	// Containment
+	if len(parents) > 0 {
+		allowed := false
+		for _, parent := range parents {
+			allowed = allowed || c.HasParent(parent)
+		}
+		if !allowed {
+			add("%s cannot be contained by %s", class, strings.Join(parents, " or "))
+		}
+	}
+
+	// Attributes
+	attrs := obj.Get(class + ".attributes")
+	var names []string
+	attrs.ForEach(func(key, _ Res) bool {
+		names = append(names, key.Str)
+		return true
+	})
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := c.Properties[name]
+		if !ok {
+			add("unknown attribute %s", name)
+			continue
+		}
+		value := attrs.Get(name).String()
+		if value == "" {
+			continue
+		}
+		// Bitmask properties take a comma separated list, e.g. ctrl: querier,nd
+		for _, v := range strings.Split(value, ",") {
+			if !prop.Valid(v) {
+				add("invalid value %q for %s, expected %s", v, name, expected(prop))
+			}
+		}
+	}
+
+	// Naming properties are required unless the DN is given
+	if !attrs.Get("dn").Exists() {
+		for _, name := range c.Naming {
+			if attrs.Get(name).String() == "" {
+				add("missing naming property %s", name)
+			}
+		}
+	}
+
+	for i, child := range obj.Get(class + ".children").Array() {
+		validate(child, fmt.Sprintf("%s.children.%d", path, i), []string{class}, violations)
+	}
+}
+
+// expected describes the valid values of a property, e.g. one of unspecified, http or a number in 0-65535.
+func expected(prop meta.Property) string {
+	var ranges []string
+	for _, r := range prop.Ranges {
+		ranges = append(ranges, fmt.Sprintf("%d-%d", r.Min, r.Max))
+	}
+	switch {
+	case len(ranges) == 0:
+		return "one of " + strings.Join(prop.Values, ", ")
+	case len(prop.Values) == 0:
+		return "a number in " + strings.Join(ranges, ", ")
+	}
+	return "one of " + strings.Join(prop.Values, ", ") + " or a number in " + strings.Join(ranges, ", ")
+}
+
+// Validate checks a POST body against the registered metadata models (see the meta package),
+// or returns meta.ErrNoModels if none is registered,
+// returning all violations at once as a ValidationError:
+// class names, containment, attribute names, enumerated values and numeric ranges, and required naming properties.
+// The parent classes are the possible classes of the parent of the top level object; pass none to skip
+// the top level containment check, e.g.
+//  err := body.Validate("fvTenant")
+func (body Body) Validate(parentClasses ...string) error {
+	if err := meta.Check(); err != nil {
+		return err
+	}
+	obj := body.Res()
+	if !obj.IsObject() || ClassName(obj) == "" {
+		return errors.New("body is not an object")
+	}
+	var violations ValidationError
+	validate(obj, "", parentClasses, &violations)
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
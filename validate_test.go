@@ -0,0 +1,120 @@
+package goaci
+
+import (
+	"testing"
+
+	"github.com/brightpuddle/goaci/meta"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// registerTestMeta registers the metadata test model and returns a function to unregister it.
+func registerTestMeta(t *testing.T) func() {
+	model, err := meta.LoadFile("meta/testdata/aci-meta.json", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		meta.Unregister(model.Version)
+	}
+}
+
+// TestBodyValidate tests the Body::Validate method.
+func TestBodyValidate(t *testing.T) {
+	// No metadata
+	assert.Error(t, Body{}.Set("fvTenant.attributes.name", "a").Validate())
+
+	defer registerTestMeta(t)()
+
+	// Valid
+	body := Body{}.
+		Set("fvTenant.attributes.name", "a").
+		SetRaw("fvTenant.children.0", Body{}.
+			Set("fvBD.attributes.name", "web").
+			Set("fvBD.attributes.arpFlood", "yes").
+			SetRaw("fvBD.children.0", Body{}.
+				Set("fvSubnet.attributes.ip", "10.0.0.1/24").
+				Set("fvSubnet.attributes.scope", "public,shared").
+				Set("fvSubnet.attributes.ctrl", "").Str).Str)
+	assert.NoError(t, body.Validate("polUni"))
+
+	// All violations at once
+	body = Body{}.
+		Set("fvTenant.attributes.descr", "no name").
+		SetRaw("fvTenant.children.0", Body{}.
+			Set("fvBD.attributes.name", "web").
+			Set("fvBD.attributes.arpflood", "yes").
+			Set("fvBD.attributes.unicastRoute", "maybe").Str).
+		SetRaw("fvTenant.children.1", Body{}.Set("fvSubnet.attributes.ip", "10.0.0.1/24").Str).
+		SetRaw("fvTenant.children.2", Body{}.Set("fvBDD.attributes.name", "typo").Str)
+	err := body.Validate("fvAp")
+	violations, ok := err.(ValidationError)
+	if assert.True(t, ok) {
+		assert.Equal(t, ValidationError{
+			{"fvTenant", "fvTenant", "fvTenant cannot be contained by fvAp"},
+			{"fvTenant", "fvTenant", "missing naming property name"},
+			{"fvTenant.children.0.fvBD", "fvBD", "unknown attribute arpflood"},
+			{"fvTenant.children.0.fvBD", "fvBD", `invalid value "maybe" for unicastRoute, expected one of no, yes`},
+			{"fvTenant.children.1.fvSubnet", "fvSubnet", "fvSubnet cannot be contained by fvTenant"},
+			{"fvTenant.children.2.fvBDD", "fvBDD", "unknown class fvBDD"},
+		}, violations)
+		assert.Contains(t, err.Error(), "fvTenant.children.2.fvBDD: unknown class fvBDD")
+	}
+
+	// Enumerated values or numeric ranges, e.g. ports
+	entry := func(port string) Body {
+		return Body{}.
+			Set("vzEntry.attributes.name", "web").
+			Set("vzEntry.attributes.etherT", "ip").
+			Set("vzEntry.attributes.dFromPort", port)
+	}
+	assert.NoError(t, entry("8080").Validate("vzFilter"))
+	assert.NoError(t, entry("https").Validate("vzFilter"))
+	err = entry("70000").Validate("vzFilter")
+	assert.Equal(t, ValidationError{{"vzEntry", "vzEntry",
+		`invalid value "70000" for dFromPort, expected one of unspecified, ftpData, smtp, dns, http, pop3, rtsp, https or a number in 0-65535`}}, err)
+	assert.Error(t, entry("web").Validate("vzFilter"))
+
+	// DN instead of naming properties
+	assert.NoError(t, Body{}.Set("fvBD.attributes.dn", "uni/tn-a/BD-web").Validate())
+
+	// Not an object
+	assert.Error(t, Body{Str: "[]"}.Validate())
+}
+
+// TestClientPostValidate tests validation in the Client::Post method.
+func TestClientPostValidate(t *testing.T) {
+	defer gock.Off()
+
+	// The metadata is checked on setup
+	_, err := NewClient(testHost, "usr", "pwd", ValidatePosts)
+	assert.Equal(t, meta.ErrNoModels, err)
+
+	defer registerTestMeta(t)()
+	client := testClient()
+	ValidatePosts(&client)
+
+	// Invalid bodies aren't sent
+	_, err = client.Post("/api/mo/uni/tn-a", Body{}.Set("fvBD.attributes.nmae", "web").Str)
+	assert.IsType(t, ValidationError{}, err)
+	assert.False(t, gock.HasUnmatchedRequest())
+
+	// Child posted to the parent DN
+	gock.New(testURL).Post("/api/mo/uni/tn-a.json").Reply(200)
+	_, err = client.Post("/api/mo/uni/tn-a", Body{}.Set("fvBD.attributes.name", "web").Str)
+	assert.NoError(t, err)
+
+	// Object posted to its own DN
+	gock.New(testURL).Post("/api/mo/uni/tn-a.json").Reply(200)
+	_, err = client.Post("/api/mo/uni/tn-a", Body{}.Set("fvTenant.attributes.name", "a").Str)
+	assert.NoError(t, err)
+
+	// Containment is checked against the parent DN
+	_, err = client.Post("/api/mo/uni/tn-a/BD-web", Body{}.Set("fvAEPg.attributes.name", "web").Str)
+	assert.Error(t, err)
+
+	// Other endpoints aren't validated
+	gock.New(testURL).Post("/api/aaaLogin.json").Reply(200)
+	_, err = client.Post("/api/aaaLogin", `{"aaaUser":{"attributes":{"name":"usr"}}}`)
+	assert.NoError(t, err)
+}